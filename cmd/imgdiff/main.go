@@ -0,0 +1,74 @@
+// Command imgdiff compares two .ppm or .png images and reports how much they
+// differ. It exits with status 1 when any color component differs by more than
+// the tolerance, which makes it usable as a regression check in CI.
+//
+// Usage:
+//
+//	imgdiff [-tolerance 0.01] [-o diff.png] expected.png actual.png
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+)
+
+// errMismatch is returned when images differ by more than the tolerance
+var errMismatch = errors.New("images differ")
+
+func main() {
+	err := run(os.Args[1:], os.Stdout, os.Stderr)
+	if errors.Is(err, errMismatch) {
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "imgdiff:", err)
+		os.Exit(2)
+	}
+}
+
+func run(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("imgdiff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	tolerance := fs.Float64("tolerance", 0, "largest allowed difference of a color component, from 0 to 1")
+	output := fs.String("o", "", "save an image highlighting mismatched pixels in red to this .png `file`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return errors.New("expected two images to compare")
+	}
+
+	expected, err := canvas.Load(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	actual, err := canvas.Load(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	d, err := canvas.Compare(expected, actual)
+	if err != nil {
+		return err
+	}
+	diff, mismatched, err := canvas.Diff(expected, actual, *tolerance)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "mismatched pixels: %d\nmax error: %.6f\nmean error: %.6f\nPSNR: %.2f dB\nSSIM: %.6f\n",
+		mismatched, d.MaxError, d.MeanError, d.PSNR, d.SSIM)
+
+	if *output != "" {
+		if err := diff.SaveToPNG(*output); err != nil {
+			return err
+		}
+	}
+	if mismatched > 0 {
+		return errMismatch
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	a := canvas.NewCanvas(4, 4)
+	b := canvas.NewCanvas(4, 4)
+	b.WritePixel(1, 1, color.NewColor(0.5, 0, 0))
+	aPath, bPath := filepath.Join(dir, "a.png"), filepath.Join(dir, "b.ppm")
+	assert.Nil(t, a.SaveToPNG(aPath))
+	assert.Nil(t, b.SaveToPPM(bPath))
+
+	var stdout bytes.Buffer
+	assert.Nil(t, run([]string{aPath, aPath}, &stdout, &bytes.Buffer{}))
+	assert.True(t, strings.Contains(stdout.String(), "mismatched pixels: 0"))
+
+	stdout.Reset()
+	diffPath := filepath.Join(dir, "diff.png")
+	err := run([]string{"-o", diffPath, aPath, bPath}, &stdout, &bytes.Buffer{})
+	assert.True(t, errors.Is(err, errMismatch))
+	assert.True(t, strings.Contains(stdout.String(), "mismatched pixels: 1"))
+	_, err = os.Stat(diffPath)
+	assert.Nil(t, err)
+
+	assert.Nil(t, run([]string{"-tolerance", "0.6", aPath, bPath}, &bytes.Buffer{}, &bytes.Buffer{}))
+
+	err = run([]string{aPath}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, errMismatch))
+	err = run([]string{aPath, filepath.Join(dir, "missing.png")}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,268 @@
+// Command raytracer renders a scene file into an image.
+//
+// Usage:
+//
+//	raytracer [flags] scene.yml
+//
+// Run raytracer -h for the list of flags.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/camera"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/render"
+	"github.com/alex-petrov-vt/raytracer/pkg/sampling"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+	"github.com/alex-petrov-vt/raytracer/pkg/tonemapping"
+	"github.com/alex-petrov-vt/raytracer/pkg/tracer"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "raytracer:", err)
+		os.Exit(1)
+	}
+}
+
+type config struct {
+	scene       string
+	output      string
+	format      string
+	width       int
+	height      int
+	samples     int
+	workers     int
+	seed        int64
+	tonemap     string
+	exposure    float64
+	checkpoint  string
+	transparent bool
+	passes      []render.Pass
+	quiet       bool
+}
+
+func parseFlags(args []string, stderr io.Writer) (*config, error) {
+	cfg := &config{}
+	fs := flag.NewFlagSet("raytracer", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: raytracer [flags] scene.yml")
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&cfg.output, "o", "out.png", "output image `path`")
+	fs.StringVar(&cfg.format, "format", "", "output format: ppm, pam, png or exr (default is taken from the output extension)")
+	fs.IntVar(&cfg.width, "width", 0, "image width, overrides the scene camera")
+	fs.IntVar(&cfg.height, "height", 0, "image height, overrides the scene camera")
+	fs.IntVar(&cfg.samples, "samples", 1, "samples per pixel")
+	fs.IntVar(&cfg.workers, "workers", 0, "number of tiles rendered concurrently (default is the number of CPUs)")
+	fs.Int64Var(&cfg.seed, "seed", 0, "seed for random sampling patterns")
+	fs.StringVar(&cfg.tonemap, "tonemap", "clamp", "tone mapping operator: clamp, reinhard or aces (not used for exr output)")
+	fs.Float64Var(&cfg.exposure, "exposure", 0, "exposure adjustment in stops")
+	fs.StringVar(&cfg.checkpoint, "checkpoint", "", "`file` to periodically save progress to and resume from")
+	fs.BoolVar(&cfg.transparent, "transparent", false, "make the background transparent instead of using the scene background color")
+	passes := fs.String("passes", "", "comma separated `list` of render passes saved next to the output as <name>.<pass>.<ext>: "+passNames(render.StandardPasses))
+	fs.BoolVar(&cfg.quiet, "q", false, "don't print progress")
+
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return nil, errors.New("expected exactly one scene file")
+	}
+	cfg.scene = fs.Arg(0)
+	if cfg.format == "" {
+		cfg.format = strings.TrimPrefix(strings.ToLower(filepath.Ext(cfg.output)), ".")
+	}
+	if _, ok := encoders[cfg.format]; !ok {
+		return nil, fmt.Errorf("unsupported output format %q", cfg.format)
+	}
+	if cfg.passes, err = parsePasses(*passes); err != nil {
+		return nil, err
+	}
+	if _, ok := operators[cfg.tonemap]; !ok {
+		return nil, fmt.Errorf("unknown tone mapping operator %q", cfg.tonemap)
+	}
+	if cfg.format == "exr" && isSet(fs, "tonemap") {
+		return nil, errors.New("-tonemap can't be used with exr output, which keeps linear colors")
+	}
+	if cfg.width < 0 || cfg.height < 0 || cfg.samples < 1 || cfg.workers < 0 {
+		return nil, errors.New("dimensions, samples and workers can't be negative and at least one sample is needed")
+	}
+	return cfg, nil
+}
+
+// isSet checks if flag name was given on the command line
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// parsePasses turns a comma separated list of names into standard render passes
+func parsePasses(list string) ([]render.Pass, error) {
+	var passes []render.Pass
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, p := range render.StandardPasses {
+			if p.Name == name {
+				passes = append(passes, p)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown render pass %q", name)
+		}
+	}
+	return passes, nil
+}
+
+func passNames(passes []render.Pass) string {
+	var names []string
+	for _, p := range passes {
+		names = append(names, p.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+var encoders = map[string]func(c *canvas.Canvas, file string) error{
+	"ppm": (*canvas.Canvas).SaveToPPM,
+	"pam": (*canvas.Canvas).SaveToPAM,
+	"png": (*canvas.Canvas).SaveToPNG,
+	"exr": (*canvas.Canvas).SaveToEXR,
+}
+
+var operators = map[string]tonemapping.Operator{
+	"clamp":    tonemapping.Clamp,
+	"reinhard": tonemapping.Reinhard,
+	"aces":     tonemapping.ACES,
+}
+
+func run(ctx context.Context, args []string, stderr io.Writer) error {
+	cfg, err := parseFlags(args, stderr)
+	if err != nil {
+		return err
+	}
+	s, err := scene.Load(cfg.scene)
+	if err != nil {
+		return err
+	}
+	if cfg.width > 0 {
+		s.Camera.Width = cfg.width
+	}
+	if cfg.height > 0 {
+		s.Camera.Height = cfg.height
+	}
+	shade, err := newShader(s, cfg.transparent)
+	if err != nil {
+		return err
+	}
+
+	opts := render.Options{
+		Workers: cfg.workers,
+		Sampler: sampling.Sampler{
+			Samples: cfg.samples,
+			Pattern: sampling.Sobol{},
+			Filter:  sampling.NewMitchell(),
+			Seed:    cfg.seed,
+		},
+	}
+	if cfg.samples == 1 {
+		// A single sample goes to the pixel center, like a pinhole camera expects
+		opts.Sampler.Pattern = sampling.Grid{}
+	}
+	if !cfg.quiet {
+		opts.Progress = func(p render.Progress) {
+			fmt.Fprintf(stderr, "\r%5.1f%% %d/%d tiles, elapsed %v, eta %v   ",
+				100*p.Fraction(), p.TilesDone, p.TilesTotal,
+				p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+		}
+	}
+	if cfg.checkpoint != "" {
+		opts.Checkpoint = &render.Checkpoint{Path: cfg.checkpoint, Tag: checkpointTag(cfg, s)}
+	}
+
+	c, passes, renderErr := render.RenderPasses(ctx, s.Camera.Width, s.Camera.Height, shade, cfg.passes, opts)
+	if !cfg.quiet {
+		fmt.Fprintln(stderr)
+	}
+	if c == nil {
+		return renderErr
+	}
+	// Partially rendered image is still saved, so that it's possible to see how
+	// far an interrupted render got
+	if cfg.format == "exr" {
+		// OpenEXR keeps linear float colors for compositing, so only exposure is
+		// applied, and passes are stored as layers of the same file
+		exposed := tonemapping.Apply(c, tonemapping.Exposure{Stops: cfg.exposure})
+		layers := append([]canvas.Layer{{Canvas: exposed}}, passes...)
+		if err := canvas.SaveLayersToEXR(layers, cfg.output, canvas.EXRZip); err != nil {
+			return err
+		}
+		return renderErr
+	}
+	mapped := tonemapping.Apply(c, tonemapping.NewPipeline(cfg.exposure, operators[cfg.tonemap]))
+	if err := encoders[cfg.format](mapped, cfg.output); err != nil {
+		return err
+	}
+	// Passes hold data rather than pictures, so they are saved without tone mapping
+	if err := canvas.SaveLayers(passes, cfg.output, encoders[cfg.format]); err != nil {
+		return err
+	}
+	return renderErr
+}
+
+// checkpointTag describes every setting that changes the rendered pixels, so
+// that a checkpoint is only resumed by the same render. Exposure and tone mapping
+// are applied after rendering and don't need to match.
+func checkpointTag(cfg *config, s *scene.Scene) string {
+	return fmt.Sprintf("%s %dx%d samples=%d seed=%d transparent=%t passes=%s",
+		cfg.scene, s.Camera.Width, s.Camera.Height, cfg.samples, cfg.seed, cfg.transparent, passNames(cfg.passes))
+}
+
+// newShader returns the function tracing rays of the scene camera through the
+// objects of the scene. Rays that miss everything show the background, or nothing
+// at all if the background is transparent.
+func newShader(s *scene.Scene, transparent bool) (render.PassShader, error) {
+	cam, err := camera.FromScene(s.Camera)
+	if err != nil {
+		return nil, err
+	}
+	world, err := tracer.NewWorld(s)
+	if err != nil {
+		return nil, err
+	}
+	if transparent {
+		world.Background = nil
+	}
+	return func(x, y float64, aovs render.AOVs) *color.Color {
+		r, ok := cam.Ray(x, y)
+		if !ok {
+			return nil
+		}
+		return world.Shade(r, aovs)
+	}, nil
+}
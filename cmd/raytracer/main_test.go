@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const emptyScene = `
+camera: {width: 8, height: 4, field-of-view: 60, from: [0, 0, -5], to: [0, 0, 0]}
+background: "#ff8000"
+`
+
+func writeScene(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "scene.yml")
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestRunPNG(t *testing.T) {
+	scenePath := writeScene(t, emptyScene)
+	out := filepath.Join(filepath.Dir(scenePath), "out.png")
+	var stderr bytes.Buffer
+
+	err := run(context.Background(), []string{"-o", out, "-width", "6", "-samples", "4", "-seed", "3", scenePath}, &stderr)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(stderr.String(), "100.0%"))
+
+	handle, err := os.Open(out)
+	assert.Nil(t, err)
+	defer handle.Close()
+	img, err := png.Decode(handle)
+	assert.Nil(t, err)
+	assert.Equal(t, 6, img.Bounds().Dx())
+	assert.Equal(t, 4, img.Bounds().Dy())
+	// Hex colors are sRGB and get encoded back to the same value
+	r, g, b, _ := img.At(3, 2).RGBA()
+	assert.Equal(t, []uint32{0xff, 0x80, 0}, []uint32{r >> 8, g >> 8, b >> 8})
+}
+
+const sphereScene = `
+camera: {width: 9, height: 9, field-of-view: 60, from: [0, 0, -5], to: [0, 0, 0]}
+background: [0, 0, 1]
+lights:
+  - at: [-10, 10, -10]
+objects:
+  - type: sphere
+    material: {color: [1, 0, 0], specular: 0}
+`
+
+func TestRunScene(t *testing.T) {
+	scenePath := writeScene(t, sphereScene)
+	dir := filepath.Dir(scenePath)
+	out := filepath.Join(dir, "out.png")
+
+	err := run(context.Background(), []string{"-q", "-passes", "id", "-o", out, scenePath}, &bytes.Buffer{})
+	assert.Nil(t, err)
+	img := decodePNG(t, out)
+	// The sphere is lit in the middle of the image and the background shows in
+	// the corners
+	r, g, b, _ := img.At(4, 4).RGBA()
+	assert.True(t, r > 0x4000 && g == 0 && b == 0, "center %x %x %x", r, g, b)
+	r, g, b, _ = img.At(0, 0).RGBA()
+	assert.Equal(t, []uint32{0, 0, 0xffff}, []uint32{r, g, b})
+
+	ids := decodePNG(t, filepath.Join(dir, "out.id.png"))
+	r, _, _, _ = ids.At(4, 4).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	_, _, _, a := ids.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0), a)
+}
+
+func decodePNG(t *testing.T, file string) image.Image {
+	handle, err := os.Open(file)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	defer handle.Close()
+	img, err := png.Decode(handle)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return img
+}
+
+func TestRunPPM(t *testing.T) {
+	scenePath := writeScene(t, emptyScene)
+	out := filepath.Join(filepath.Dir(scenePath), "out.img")
+	var stderr bytes.Buffer
+
+	err := run(context.Background(), []string{"-q", "-format", "ppm", "-o", out, "-height", "1", "-width", "1", scenePath}, &stderr)
+	assert.Nil(t, err)
+	assert.Equal(t, "", stderr.String())
+	data, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.Equal(t, "P3\n1 1\n255\n255 128 0\n", string(data))
+}
+
+func TestRunTransparentPAM(t *testing.T) {
+	scenePath := writeScene(t, emptyScene)
+	out := filepath.Join(filepath.Dir(scenePath), "out.pam")
+
+	err := run(context.Background(), []string{"-q", "-transparent", "-o", out, "-width", "1", "-height", "1", scenePath}, &bytes.Buffer{})
+	assert.Nil(t, err)
+	data, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasSuffix(data, []byte("ENDHDR\n\x00\x00\x00\x00")))
+}
+
+func TestRunCheckpointTransparency(t *testing.T) {
+	scenePath := writeScene(t, emptyScene)
+	dir := filepath.Dir(scenePath)
+	checkpoint := filepath.Join(dir, "render.ckpt")
+	args := []string{"-q", "-checkpoint", checkpoint, "-o", filepath.Join(dir, "out.pam"), scenePath}
+
+	assert.Nil(t, run(context.Background(), args, &bytes.Buffer{}))
+	err := run(context.Background(), append([]string{"-transparent"}, args...), &bytes.Buffer{})
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "can't resume from checkpoint"), err.Error())
+	}
+}
+
+func TestRunPasses(t *testing.T) {
+	scenePath := writeScene(t, emptyScene)
+	out := filepath.Join(filepath.Dir(scenePath), "out.ppm")
+
+	err := run(context.Background(), []string{"-q", "-passes", "depth, id", "-o", out, "-width", "2", "-height", "1", scenePath}, &bytes.Buffer{})
+	assert.Nil(t, err)
+	for _, file := range []string{"out.ppm", "out.depth.ppm", "out.id.ppm"} {
+		_, err := os.Stat(filepath.Join(filepath.Dir(scenePath), file))
+		assert.Nil(t, err, file)
+	}
+}
+
+func TestRunEXR(t *testing.T) {
+	scenePath := writeScene(t, emptyScene)
+	dir := filepath.Dir(scenePath)
+	out := filepath.Join(dir, "out.exr")
+
+	err := run(context.Background(), []string{"-q", "-passes", "depth", "-o", out, "-width", "2", "-height", "1", scenePath}, &bytes.Buffer{})
+	assert.Nil(t, err)
+	data, err := os.ReadFile(out)
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte{0x76, 0x2f, 0x31, 0x01}))
+	assert.True(t, bytes.Contains(data, []byte("depth.R\x00")))
+	// Passes are layers of the same file
+	_, err = os.Stat(filepath.Join(dir, "out.depth.exr"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunErrors(t *testing.T) {
+	scenePath := writeScene(t, emptyScene)
+	singular := writeScene(t, emptyScene+"objects:\n  - type: sphere\n    transform: [[scale, 0, 1, 1]]\n")
+
+	tests := map[string]struct {
+		args []string
+		msg  string
+	}{
+		"no scene":         {args: []string{}, msg: "expected exactly one scene file"},
+		"unknown format":   {args: []string{"-o", "out.jpg", scenePath}, msg: `unsupported output format "jpg"`},
+		"unknown tonemap":  {args: []string{"-tonemap", "magic", scenePath}, msg: `unknown tone mapping operator "magic"`},
+		"unknown pass":     {args: []string{"-passes", "depth,magic", scenePath}, msg: `unknown render pass "magic"`},
+		"zero samples":     {args: []string{"-samples", "0", scenePath}, msg: "at least one sample"},
+		"missing scene":    {args: []string{"nothing.yml"}, msg: "nothing.yml"},
+		"singular shape":   {args: []string{"-q", singular}, msg: "scene.yml:5: sphere"},
+		"tonemap with exr": {args: []string{"-o", "out.exr", "-tonemap", "aces", scenePath}, msg: "-tonemap can't be used with exr output"},
+	}
+
+	for name, tc := range tests {
+		err := run(context.Background(), tc.args, &bytes.Buffer{})
+		if assert.NotNil(t, err, name) {
+			assert.True(t, strings.Contains(err.Error(), tc.msg), "%s: %q doesn't contain %q", name, err, tc.msg)
+		}
+	}
+}
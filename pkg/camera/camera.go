@@ -0,0 +1,87 @@
+// Package camera generates the rays that are traced for every point of the image.
+// All cameras use the same conventions as the view transformation: in camera
+// space the eye is at the origin looking towards negative z, with positive y up
+// and positive x to the left of the image.
+package camera
+
+import (
+	"context"
+	"errors"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/ray"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/render"
+)
+
+// Camera maps points of the image to rays in world space
+type Camera interface {
+	// Size returns the dimensions of the image in pixels
+	Size() (width, height int)
+	// Ray returns the ray seen at point (x, y) of the image, where the pixel at
+	// [w][h] covers the area from (w, h) to (w+1, h+1). It returns false if the
+	// camera sees nothing there, like the corners of a circular fisheye image.
+	Ray(x, y float64) (*ray.Ray, bool)
+}
+
+// Tracer returns the color seen along a ray, or nil if nothing is visible
+type Tracer func(r *ray.Ray) *color.Color
+
+// Shader returns a render.Shader that traces the rays of camera c, parts of the
+// image where the camera sees nothing stay transparent
+func Shader(c Camera, trace Tracer) render.Shader {
+	return func(x, y float64) *color.Color {
+		r, ok := c.Ray(x, y)
+		if !ok {
+			return nil
+		}
+		return trace(r)
+	}
+}
+
+// Render renders the image seen by camera c into a canvas of the camera size,
+// see render.Render for how opts are used
+func Render(ctx context.Context, c Camera, trace Tracer, opts render.Options) (*canvas.Canvas, error) {
+	w, h := c.Size()
+	return render.Render(ctx, w, h, Shader(c, trace), opts)
+}
+
+// frame holds what every camera needs: the image dimensions and the transformation
+// from camera space to world space, which is the inverse of the view transformation
+type frame struct {
+	width, height int
+	toWorld       *matrix.Matrix
+}
+
+func newFrame(width, height int, transform *matrix.Matrix) (frame, error) {
+	if width <= 0 || height <= 0 {
+		return frame{}, errors.New("camera dimensions must be positive")
+	}
+	if transform.Width != 4 || transform.Height != 4 {
+		return frame{}, errors.New("camera transformation matrix must be 4x4")
+	}
+	toWorld, err := matrix.GetInverse(transform)
+	if err != nil {
+		return frame{}, err
+	}
+	return frame{width, height, toWorld}, nil
+}
+
+// Size returns the dimensions of the image in pixels
+func (f frame) Size() (int, int) {
+	return f.width, f.height
+}
+
+// ray transforms a ray given in camera space to world space, normalizing its
+// direction
+func (f frame) ray(origin, direction *vector.Vector) *ray.Ray {
+	// Transformation is checked to be 4x4 when the frame is created, so this
+	// can't fail
+	r, _ := ray.Transform(ray.NewRay(origin, direction), f.toWorld)
+	if d, err := vector.Normalize(r.Direction); err == nil {
+		r.Direction = d
+	}
+	return r
+}
@@ -0,0 +1,197 @@
+package camera
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/ray"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/render"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+	"github.com/stretchr/testify/assert"
+)
+
+func assertRay(t *testing.T, c Camera, x, y float64, origin, direction *vector.Vector) {
+	t.Helper()
+	r, ok := c.Ray(x, y)
+	if !assert.True(t, ok, "no ray at (%v, %v)", x, y) {
+		return
+	}
+	assert.True(t, vector.Equals(origin, r.Origin), "origin %v", r.Origin)
+	assert.True(t, vector.Equals(direction, r.Direction), "direction %v", r.Direction)
+}
+
+func TestPerspective(t *testing.T) {
+	c, err := NewPerspective(201, 101, math.Pi/2, matrix.Identity(4))
+	assert.NoError(t, err)
+	w, h := c.Size()
+	assert.Equal(t, 201, w)
+	assert.Equal(t, 101, h)
+
+	assertRay(t, c, 100.5, 50.5, vector.NewPoint(0, 0, 0), vector.NewVector(0, 0, -1))
+	assertRay(t, c, 0.5, 0.5, vector.NewPoint(0, 0, 0), vector.NewVector(0.66519, 0.33259, -0.66851))
+
+	transform, err := matrix.Multiply(matrix.RotationY(math.Pi/4), matrix.Translation(0, -2, 5))
+	assert.NoError(t, err)
+	c, err = NewPerspective(201, 101, math.Pi/2, transform)
+	assert.NoError(t, err)
+	assertRay(t, c, 100.5, 50.5, vector.NewPoint(0, 2, -5), vector.NewVector(math.Sqrt2/2, 0, -math.Sqrt2/2))
+}
+
+func TestDistantCamera(t *testing.T) {
+	from := vector.NewPoint(0, 0, -2e5)
+	transform, err := matrix.ViewTransform(from, vector.NewPoint(0, 0, 0), vector.NewVector(0, 1, 0))
+	assert.NoError(t, err)
+
+	cameras := map[string]func() (Camera, error){
+		"perspective":     func() (Camera, error) { return NewPerspective(10, 10, math.Pi/3, transform) },
+		"orthographic":    func() (Camera, error) { return NewOrthographic(10, 10, 1e-3, transform) },
+		"fisheye":         func() (Camera, error) { return NewFisheye(10, 10, math.Pi, transform) },
+		"equirectangular": func() (Camera, error) { return NewEquirectangular(10, 10, transform) },
+	}
+	for name, create := range cameras {
+		t.Run(name, func(t *testing.T) {
+			c, err := create()
+			if !assert.NoError(t, err) {
+				return
+			}
+			assertRay(t, c, 5, 5, from, vector.NewVector(0, 0, 1))
+		})
+	}
+
+	s, err := NewStereo(10, 10, math.Pi/3, 0.064, transform)
+	assert.NoError(t, err)
+	assertRay(t, s, 5, 5, vector.NewPoint(-0.032, 0, -2e5), vector.NewVector(0, 0, 1))
+}
+
+func TestFromScene(t *testing.T) {
+	transform, err := matrix.ViewTransform(vector.NewPoint(0, 0, 5), vector.NewPoint(0, 0, 0), vector.NewVector(0, 1, 0))
+	assert.NoError(t, err)
+	c, err := FromScene(&scene.Camera{Width: 10, Height: 10, FieldOfView: math.Pi / 3, Transform: transform})
+	assert.NoError(t, err)
+	assertRay(t, c, 5, 5, vector.NewPoint(0, 0, 5), vector.NewVector(0, 0, -1))
+}
+
+func TestOrthographic(t *testing.T) {
+	c, err := NewOrthographic(200, 100, 4, matrix.Translation(0, 0, -10))
+	assert.NoError(t, err)
+
+	assertRay(t, c, 100, 50, vector.NewPoint(0, 0, 10), vector.NewVector(0, 0, -1))
+	assertRay(t, c, 0, 0, vector.NewPoint(2, 1, 10), vector.NewVector(0, 0, -1))
+	assertRay(t, c, 200, 100, vector.NewPoint(-2, -1, 10), vector.NewVector(0, 0, -1))
+}
+
+func TestFisheye(t *testing.T) {
+	c, err := NewFisheye(100, 100, math.Pi, matrix.Identity(4))
+	assert.NoError(t, err)
+
+	origin := vector.NewPoint(0, 0, 0)
+	assertRay(t, c, 50, 50, origin, vector.NewVector(0, 0, -1))
+	// The edge of the image circle is 90 degrees from the view direction
+	assertRay(t, c, 100, 50, origin, vector.NewVector(-1, 0, 0))
+	assertRay(t, c, 50, 0, origin, vector.NewVector(0, 1, 0))
+	// Half way to the edge is half of the angle
+	assertRay(t, c, 50, 75, origin, vector.NewVector(0, -math.Sqrt2/2, -math.Sqrt2/2))
+
+	_, ok := c.Ray(1, 1)
+	assert.False(t, ok, "corners are outside of the image circle")
+
+	c, err = NewFisheye(100, 100, 2*math.Pi, matrix.Identity(4))
+	assert.NoError(t, err)
+	assertRay(t, c, 100, 50, origin, vector.NewVector(0, 0, 1))
+}
+
+func TestEquirectangular(t *testing.T) {
+	c, err := NewEquirectangular(360, 180, matrix.Identity(4))
+	assert.NoError(t, err)
+
+	origin := vector.NewPoint(0, 0, 0)
+	tests := map[string]struct {
+		x, y      float64
+		direction *vector.Vector
+	}{
+		"forward": {x: 180, y: 90, direction: vector.NewVector(0, 0, -1)},
+		"right":   {x: 270, y: 90, direction: vector.NewVector(-1, 0, 0)},
+		"left":    {x: 90, y: 90, direction: vector.NewVector(1, 0, 0)},
+		"behind":  {x: 0, y: 90, direction: vector.NewVector(0, 0, 1)},
+		"up":      {x: 123, y: 0, direction: vector.NewVector(0, 1, 0)},
+		"down":    {x: 42, y: 180, direction: vector.NewVector(0, -1, 0)},
+		"above":   {x: 180, y: 45, direction: vector.NewVector(0, math.Sqrt2/2, -math.Sqrt2/2)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assertRay(t, c, tc.x, tc.y, origin, tc.direction)
+		})
+	}
+}
+
+func TestStereo(t *testing.T) {
+	c, err := NewStereo(100, 50, math.Pi/2, 0.064, matrix.Identity(4))
+	assert.NoError(t, err)
+	w, h := c.Size()
+	assert.Equal(t, 200, w)
+	assert.Equal(t, 50, h)
+
+	// Positive x is to the left of the camera
+	assertRay(t, c, 50, 25, vector.NewPoint(0.032, 0, 0), vector.NewVector(0, 0, -1))
+	assertRay(t, c, 150, 25, vector.NewPoint(-0.032, 0, 0), vector.NewVector(0, 0, -1))
+
+	_, err = NewStereo(100, 50, math.Pi/2, -1, matrix.Identity(4))
+	assert.Error(t, err)
+}
+
+func TestInvalidCameras(t *testing.T) {
+	identity := matrix.Identity(4)
+	singular := matrix.Scaling(1, 0, 1)
+	tests := map[string]func() (Camera, error){
+		"zero width": func() (Camera, error) { return NewPerspective(0, 10, 1, identity) },
+		"negative height": func() (Camera, error) {
+			return NewOrthographic(10, -1, 1, identity)
+		},
+		"wide perspective": func() (Camera, error) { return NewPerspective(10, 10, math.Pi, identity) },
+		"zero view width":  func() (Camera, error) { return NewOrthographic(10, 10, 0, identity) },
+		"wide fisheye":     func() (Camera, error) { return NewFisheye(10, 10, 7, identity) },
+		"singular":         func() (Camera, error) { return NewEquirectangular(10, 10, singular) },
+		"not 4x4":          func() (Camera, error) { return NewEquirectangular(10, 10, matrix.Identity(3)) },
+		"stereo not 4x4":   func() (Camera, error) { return NewStereo(10, 10, 1, 1, matrix.Identity(3)) },
+	}
+
+	for name, create := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := create()
+			assert.Error(t, err)
+		})
+	}
+}
+
+// sky is white above the horizon and black below it
+func sky(r *ray.Ray) *color.Color {
+	if r.Direction.Y > 0 {
+		return color.NewColor(1, 1, 1)
+	}
+	return color.NewColor(0, 0, 0)
+}
+
+func TestRender(t *testing.T) {
+	c, err := NewFisheye(20, 10, math.Pi, matrix.Identity(4))
+	assert.NoError(t, err)
+
+	img, err := Render(context.Background(), c, sky, render.Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, img.Width)
+	assert.Equal(t, 10, img.Height)
+
+	p, _ := img.GetPixel(10, 2)
+	assert.True(t, color.Equals(p, color.NewColor(1, 1, 1)))
+	p, _ = img.GetPixel(10, 7)
+	assert.True(t, color.Equals(p, color.NewColor(0, 0, 0)))
+	a, _ := img.GetAlpha(10, 7)
+	assert.Equal(t, 1.0, a)
+	// Outside of the image circle nothing is visible
+	a, _ = img.GetAlpha(0, 0)
+	assert.Equal(t, 0.0, a)
+}
@@ -0,0 +1,142 @@
+package camera
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/ray"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+)
+
+// Perspective is a pinhole camera, where every ray starts at the eye and goes
+// through a point of the image plane one unit in front of it
+type Perspective struct {
+	frame
+	halfWidth, halfHeight, pixelSize float64
+}
+
+// NewPerspective creates a perspective camera with horizontal angle of view fov
+// in radians, oriented by view transformation transform
+func NewPerspective(width, height int, fov float64, transform *matrix.Matrix) (*Perspective, error) {
+	if fov <= 0 || fov >= math.Pi {
+		return nil, errors.New("perspective field of view must be between 0 and pi")
+	}
+	f, err := newFrame(width, height, transform)
+	if err != nil {
+		return nil, err
+	}
+	halfWidth := math.Tan(fov / 2)
+	pixelSize := 2 * halfWidth / float64(width)
+	return &Perspective{f, halfWidth, pixelSize * float64(height) / 2, pixelSize}, nil
+}
+
+// FromScene creates the perspective camera described by a scene
+func FromScene(c *scene.Camera) (*Perspective, error) {
+	return NewPerspective(c.Width, c.Height, c.FieldOfView, c.Transform)
+}
+
+// Ray returns the ray seen at point (x, y) of the image
+func (p *Perspective) Ray(x, y float64) (*ray.Ray, bool) {
+	target := vector.NewPoint(p.halfWidth-x*p.pixelSize, p.halfHeight-y*p.pixelSize, -1)
+	origin := vector.NewPoint(0, 0, 0)
+	return p.ray(origin, vector.Subtract(target, origin)), true
+}
+
+// Orthographic is a camera where all rays are parallel, so that objects keep
+// their size regardless of the distance, as needed for technical drawings
+type Orthographic struct {
+	frame
+	halfWidth, halfHeight, pixelSize float64
+}
+
+// NewOrthographic creates an orthographic camera that sees viewWidth units of
+// the world across the image, oriented by view transformation transform
+func NewOrthographic(width, height int, viewWidth float64, transform *matrix.Matrix) (*Orthographic, error) {
+	if viewWidth <= 0 {
+		return nil, errors.New("orthographic view width must be positive")
+	}
+	f, err := newFrame(width, height, transform)
+	if err != nil {
+		return nil, err
+	}
+	pixelSize := viewWidth / float64(width)
+	return &Orthographic{f, viewWidth / 2, pixelSize * float64(height) / 2, pixelSize}, nil
+}
+
+// Ray returns the ray seen at point (x, y) of the image
+func (o *Orthographic) Ray(x, y float64) (*ray.Ray, bool) {
+	origin := vector.NewPoint(o.halfWidth-x*o.pixelSize, o.halfHeight-y*o.pixelSize, 0)
+	return o.ray(origin, vector.NewVector(0, 0, -1)), true
+}
+
+// Fisheye is an equidistant fisheye camera, where the angle between a ray and the
+// view direction grows linearly with the distance from the center of the image.
+// The image is a circle inscribed into the smaller of its dimensions and there
+// are no rays outside of it.
+type Fisheye struct {
+	frame
+	fov float64
+}
+
+// NewFisheye creates a fisheye camera with angle of view fov in radians across
+// the image circle, up to 2*pi, oriented by view transformation transform
+func NewFisheye(width, height int, fov float64, transform *matrix.Matrix) (*Fisheye, error) {
+	if fov <= 0 || fov > 2*math.Pi {
+		return nil, errors.New("fisheye field of view must be between 0 and 2*pi")
+	}
+	f, err := newFrame(width, height, transform)
+	if err != nil {
+		return nil, err
+	}
+	return &Fisheye{f, fov}, nil
+}
+
+// Ray returns the ray seen at point (x, y) of the image, or false if the point
+// is outside of the image circle
+func (f *Fisheye) Ray(x, y float64) (*ray.Ray, bool) {
+	w, h := float64(f.width), float64(f.height)
+	radius := math.Min(w, h) / 2
+	// Coordinates relative to the image circle with right and up positive
+	u, v := (x-w/2)/radius, (h/2-y)/radius
+	r := math.Hypot(u, v)
+	if r > 1 {
+		return nil, false
+	}
+	theta := r * f.fov / 2
+	phi := math.Atan2(v, u)
+	sinTheta := math.Sin(theta)
+	direction := vector.NewVector(-sinTheta*math.Cos(phi), sinTheta*math.Sin(phi), -math.Cos(theta))
+	return f.ray(vector.NewPoint(0, 0, 0), direction), true
+}
+
+// Equirectangular is a panoramic camera that sees the whole sphere around it,
+// with longitude going across the image and latitude going down from the top,
+// which is the format used for 360 degree images
+type Equirectangular struct {
+	frame
+}
+
+// NewEquirectangular creates a panoramic camera, where the view direction of
+// transformation transform is at the center of the image
+func NewEquirectangular(width, height int, transform *matrix.Matrix) (*Equirectangular, error) {
+	f, err := newFrame(width, height, transform)
+	if err != nil {
+		return nil, err
+	}
+	return &Equirectangular{f}, nil
+}
+
+// Ray returns the ray seen at point (x, y) of the image
+func (e *Equirectangular) Ray(x, y float64) (*ray.Ray, bool) {
+	longitude := (x/float64(e.width) - 0.5) * 2 * math.Pi
+	latitude := (0.5 - y/float64(e.height)) * math.Pi
+	cosLatitude := math.Cos(latitude)
+	direction := vector.NewVector(
+		-cosLatitude*math.Sin(longitude),
+		math.Sin(latitude),
+		-cosLatitude*math.Cos(longitude),
+	)
+	return e.ray(vector.NewPoint(0, 0, 0), direction), true
+}
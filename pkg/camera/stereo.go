@@ -0,0 +1,61 @@
+package camera
+
+import (
+	"errors"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/ray"
+)
+
+// Stereo is a pair of perspective cameras with parallel view directions, one for
+// each eye. It renders both views side by side into a single image twice as
+// wide as each view, with the left eye on the left.
+type Stereo struct {
+	Left, Right *Perspective
+}
+
+// NewStereo creates a stereo pair of width x height perspective cameras with
+// horizontal angle of view fov in radians, placed separation units apart and
+// centered on the eye of view transformation transform
+func NewStereo(width, height int, fov, separation float64, transform *matrix.Matrix) (*Stereo, error) {
+	if separation < 0 {
+		return nil, errors.New("stereo eye separation can't be negative")
+	}
+	// In camera space positive x points to the left, so the left eye is moved
+	// there and the world is moved the opposite way
+	left, err := eye(width, height, fov, -separation/2, transform)
+	if err != nil {
+		return nil, err
+	}
+	right, err := eye(width, height, fov, separation/2, transform)
+	if err != nil {
+		return nil, err
+	}
+	return &Stereo{left, right}, nil
+}
+
+func eye(width, height int, fov, shift float64, transform *matrix.Matrix) (*Perspective, error) {
+	if transform.Width != 4 || transform.Height != 4 {
+		return nil, errors.New("camera transformation matrix must be 4x4")
+	}
+	t, err := matrix.Multiply(matrix.Translation(shift, 0, 0), transform)
+	if err != nil {
+		return nil, err
+	}
+	return NewPerspective(width, height, fov, t)
+}
+
+// Size returns the dimensions of the side by side image in pixels
+func (s *Stereo) Size() (int, int) {
+	w, h := s.Left.Size()
+	return 2 * w, h
+}
+
+// Ray returns the ray seen at point (x, y) of the side by side image
+func (s *Stereo) Ray(x, y float64) (*ray.Ray, bool) {
+	w, _ := s.Left.Size()
+	if x < float64(w) {
+		return s.Left.Ray(x, y)
+	}
+	return s.Right.Ray(x-float64(w), y)
+}
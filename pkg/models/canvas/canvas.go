@@ -18,23 +18,70 @@ const (
 )
 
 // Canvas is a representation of a screen of dimensions widht x height where all
-// of pixels' colors are stored in internal 2D Colors slice.
+// of pixels' colors are stored in internal 2D Colors slice. Opacity of pixels is
+// stored in Alpha slice of the same dimensions, and colors of partially
+// transparent pixels are premultiplied by their alpha. Nil Alpha means every
+// pixel is opaque, so canvases created without NewCanvas keep working.
 type Canvas struct {
 	Height, Width int
 	Colors        [][]*color.Color
+	Alpha         [][]float64
 }
 
 // NewCanvas creates new canvas of dimensions w x h (widht x height) and initializes
-// all pixels to black color.
+// all pixels to opaque black color.
 func NewCanvas(w, h int) *Canvas {
 	colors := newColorMap(w, h)
 	return &Canvas{
 		Height: h,
 		Width:  w,
 		Colors: colors,
+		Alpha:  newAlphaMap(w, h, 1),
 	}
 }
 
+// NewTransparentCanvas creates new canvas of dimensions w x h with every pixel
+// fully transparent
+func NewTransparentCanvas(w, h int) *Canvas {
+	c := NewCanvas(w, h)
+	c.Alpha = newAlphaMap(w, h, 0)
+	return c
+}
+
+func newAlphaMap(w, h int, alpha float64) [][]float64 {
+	result := make([][]float64, h)
+	for row := range result {
+		result[row] = newAlphaRow(w, alpha)
+	}
+	return result
+}
+
+func newAlphaRow(w int, alpha float64) []float64 {
+	result := make([]float64, w)
+	for i := range result {
+		result[i] = alpha
+	}
+	return result
+}
+
+// alpha returns the alpha of the pixel located at [w][h], which must be inside
+// of the canvas
+func (c *Canvas) alpha(w, h int) float64 {
+	if c.Alpha == nil {
+		return 1
+	}
+	return c.Alpha[h][w]
+}
+
+// alphaRow returns alpha of the pixels in row h, which must be inside of the
+// canvas. It must not be modified.
+func (c *Canvas) alphaRow(h int) []float64 {
+	if c.Alpha == nil {
+		return newAlphaRow(c.Width, 1)
+	}
+	return c.Alpha[h]
+}
+
 func newColorMap(w, h int) [][]*color.Color {
 	colors := make([][]*color.Color, h)
 	for row := range colors {
@@ -46,15 +93,34 @@ func newColorMap(w, h int) [][]*color.Color {
 	return colors
 }
 
-// WritePixel writes a color provided in col to the pixel located at [w][h]
+// WritePixel writes a color provided in col to the pixel located at [w][h] and
+// makes the pixel opaque
 func (c *Canvas) WritePixel(w, h int, col *color.Color) {
+	c.WritePixelAlpha(w, h, col, 1)
+}
+
+// WritePixelAlpha writes a color premultiplied by alpha and the alpha itself to
+// the pixel located at [w][h]
+func (c *Canvas) WritePixelAlpha(w, h int, col *color.Color, alpha float64) {
 	if w < 0 || w >= c.Width || h < 0 || h >= c.Height {
 		return
 	}
+	if c.Alpha == nil {
+		c.Alpha = newAlphaMap(c.Width, c.Height, 1)
+	}
 	c.Colors[h][w] = col
+	c.Alpha[h][w] = alpha
+}
+
+// GetAlpha returns the alpha of the pixel located at [w][h]
+func (c *Canvas) GetAlpha(w, h int) (float64, error) {
+	if w < 0 || w >= c.Width || h < 0 || h >= c.Height {
+		return 0, errors.New("attempt to access pixel outside of canvas")
+	}
+	return c.alpha(w, h), nil
 }
 
-//  GetPixel returns a color of the pixel localted at [w][h]
+// GetPixel returns a color of the pixel localted at [w][h]
 func (c *Canvas) GetPixel(w, h int) (*color.Color, error) {
 	if w < 0 || w >= c.Width || h < 0 || h >= c.Height {
 		return nil, errors.New("attempt to access pixel outside of canvas")
@@ -62,7 +128,8 @@ func (c *Canvas) GetPixel(w, h int) (*color.Color, error) {
 	return c.Colors[h][w], nil
 }
 
-// SaveToPPM saves canvas to a .ppm file
+// SaveToPPM saves canvas to a .ppm file. PPM has no alpha channel, so partially
+// transparent pixels end up composited over black.
 func (c *Canvas) SaveToPPM(file string) error {
 	handle, err := os.Create(file)
 	if err != nil {
@@ -35,6 +35,55 @@ func TestWritePixel(t *testing.T) {
 
 }
 
+func TestAlpha(t *testing.T) {
+	c := NewCanvas(2, 1)
+	a, err := c.GetAlpha(0, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, a)
+
+	c.WritePixelAlpha(1, 0, color.NewColor(0.25, 0, 0), 0.5)
+	a, _ = c.GetAlpha(1, 0)
+	assert.Equal(t, 0.5, a)
+	_, err = c.GetAlpha(2, 0)
+	assert.NotNil(t, err)
+
+	c = NewTransparentCanvas(2, 1)
+	a, _ = c.GetAlpha(1, 0)
+	assert.Equal(t, 0.0, a)
+	c.WritePixel(1, 0, color.NewColor(1, 0, 0))
+	a, _ = c.GetAlpha(1, 0)
+	assert.Equal(t, 1.0, a)
+}
+
+func TestCanvasWithoutAlpha(t *testing.T) {
+	literal := func() *Canvas {
+		return &Canvas{Width: 2, Height: 1, Colors: [][]*color.Color{{color.NewColor(1, 0, 0), color.NewColor(0, 1, 0)}}}
+	}
+	c := literal()
+	a, err := c.GetAlpha(1, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, a)
+
+	var buf bytes.Buffer
+	assert.Nil(t, writePNG(&buf, c))
+	assert.Nil(t, writePAM(&buf, c))
+	assert.Nil(t, writeEXR(&buf, []Layer{{Canvas: c}}, EXRNoCompression))
+	assert.Equal(t, []float64{1, 1}, c.FlipVertical().Alpha[0])
+	resized, err := c.Resize(4, 2, Lanczos)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1, resized.Alpha[1][3], 1e-9)
+
+	c.WritePixelAlpha(0, 0, color.NewColor(0.5, 0, 0), 0.5)
+	a, _ = c.GetAlpha(0, 0)
+	assert.Equal(t, 0.5, a)
+	a, _ = c.GetAlpha(1, 0)
+	assert.Equal(t, 1.0, a)
+
+	c = literal()
+	c.WritePixel(1, 0, color.NewColor(0, 0, 1))
+	assert.Equal(t, [][]float64{{1, 1}}, c.Alpha)
+}
+
 func TestCanvasToPPM(t *testing.T) {
 	colors := []*color.Color{color.NewColor(1.5, 0, 0), color.NewColor(0, 0.5, 0),
 		color.NewColor(-0.5, 0, 1)}
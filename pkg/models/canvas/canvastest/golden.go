@@ -0,0 +1,80 @@
+// Package canvastest provides helpers for testing rendered canvases against
+// golden images stored next to the tests.
+package canvastest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+var update = flag.Bool("update-golden", false, "overwrite golden images with rendered canvases")
+
+// AssertGolden fails the test if any color component of c differs from the golden
+// .ppm or .png image at path by more than tolerance. Golden images are 8-bit, so
+// c is quantized the same way encoders do before comparing.
+//
+// Running tests with -update-golden writes c to path instead. When the images
+// don't match, the rendered canvas and a diff image are saved to a temporary
+// directory, which is printed in the test log.
+func AssertGolden(t testing.TB, c *canvas.Canvas, path string, tolerance float64) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("can't create golden image directory: %v", err)
+		}
+		if err := save(c, path); err != nil {
+			t.Fatalf("can't update golden image: %v", err)
+		}
+		return
+	}
+
+	golden, err := canvas.Load(path)
+	if err != nil {
+		t.Fatalf("can't load golden image (run with -update-golden to create it): %v", err)
+		return
+	}
+	got := Quantize(c)
+	diff, err := canvas.Compare(got, golden)
+	if err != nil {
+		t.Fatalf("can't compare with golden image %s: %v", path, err)
+		return
+	}
+	if diff.MaxError <= tolerance {
+		return
+	}
+
+	diffCanvas, mismatched, _ := canvas.Diff(got, golden, tolerance)
+	dir, err := os.MkdirTemp("", "golden")
+	if err == nil {
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		_ = c.SaveToPNG(filepath.Join(dir, base+".actual.png"))
+		_ = diffCanvas.SaveToPNG(filepath.Join(dir, base+".diff.png"))
+	}
+	t.Fatalf("canvas differs from golden image %s in %d pixels: max error %.4f, mean error %.4f, PSNR %.2f dB, SSIM %.4f; actual and diff images are in %s",
+		path, mismatched, diff.MaxError, diff.MeanError, diff.PSNR, diff.SSIM, dir)
+}
+
+// Quantize returns a copy of c with every color rounded to the closest value
+// representable in an 8-bit image
+func Quantize(c *canvas.Canvas) *canvas.Canvas {
+	result := canvas.NewCanvas(c.Width, c.Height)
+	for h, row := range c.Colors {
+		for w, col := range row {
+			result.WritePixel(w, h, color.Scale(color.ColorTo255Range(col), 1.0/255))
+		}
+	}
+	return result
+}
+
+func save(c *canvas.Canvas, path string) error {
+	if strings.ToLower(filepath.Ext(path)) == ".ppm" {
+		return c.SaveToPPM(path)
+	}
+	return c.SaveToPNG(path)
+}
@@ -0,0 +1,67 @@
+package canvastest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func gradientCanvas() *canvas.Canvas {
+	c := canvas.NewCanvas(16, 8)
+	for h := 0; h < c.Height; h++ {
+		for w := 0; w < c.Width; w++ {
+			c.WritePixel(w, h, color.NewColor(float64(w)/15, float64(h)/7, 0.5))
+		}
+	}
+	return c
+}
+
+func TestAssertGolden(t *testing.T) {
+	AssertGolden(t, gradientCanvas(), "testdata/gradient.png", 0)
+	AssertGolden(t, gradientCanvas(), "testdata/gradient.ppm", 0)
+}
+
+// recorder catches failures instead of failing the test that uses it
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestAssertGoldenMismatch(t *testing.T) {
+	if *update {
+		t.Skip("golden images are being updated")
+	}
+	c := gradientCanvas()
+	c.WritePixel(3, 3, color.NewColor(1, 1, 1))
+
+	tests := map[string]struct {
+		path      string
+		tolerance float64
+		fails     bool
+	}{
+		"mismatch":         {path: "testdata/gradient.png", tolerance: 0.1, fails: true},
+		"within tolerance": {path: "testdata/gradient.png", tolerance: 1, fails: false},
+		"missing golden":   {path: filepath.Join(t.TempDir(), "missing.png"), tolerance: 1, fails: true},
+		"ppm golden":       {path: "testdata/gradient.ppm", tolerance: 0.1, fails: true},
+	}
+
+	for name, tc := range tests {
+		r := &recorder{TB: t}
+		AssertGolden(r, c, tc.path, tc.tolerance)
+		assert.Equal(t, tc.fails, r.failed, name)
+	}
+}
+
+func TestQuantize(t *testing.T) {
+	c := canvas.NewCanvas(1, 1)
+	c.WritePixel(0, 0, color.NewColor(0.5, 1.5, -1))
+	p, _ := Quantize(c).GetPixel(0, 0)
+	assert.True(t, color.Equals(p, color.NewColor(128.0/255, 1, 0)))
+}
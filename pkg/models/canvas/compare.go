@@ -0,0 +1,146 @@
+package canvas
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+// ssimWindow is the side of the square window SSIM is computed over
+const ssimWindow = 7
+
+// Difference summarizes how much two canvases differ. Errors are measured per
+// color component.
+type Difference struct {
+	// MaxError is the largest absolute difference of a single component
+	MaxError float64
+	// MeanError is the average absolute difference of all components
+	MeanError float64
+	// PSNR is the peak signal-to-noise ratio in decibels for the peak value of 1,
+	// it's +Inf for identical canvases
+	PSNR float64
+	// SSIM is the structural similarity index of the luminance of canvases, 1 for
+	// identical canvases
+	SSIM float64
+}
+
+// Compare computes the difference between two canvases of the same dimensions
+func Compare(c1, c2 *Canvas) (*Difference, error) {
+	if err := checkSameSize(c1, c2); err != nil {
+		return nil, err
+	}
+	d := &Difference{}
+	sumAbs, sumSquares := 0.0, 0.0
+	for h := 0; h < c1.Height; h++ {
+		for w := 0; w < c1.Width; w++ {
+			p1, p2 := c1.Colors[h][w], c2.Colors[h][w]
+			for _, e := range []float64{p1.Red - p2.Red, p1.Green - p2.Green, p1.Blue - p2.Blue} {
+				e = math.Abs(e)
+				d.MaxError = math.Max(d.MaxError, e)
+				sumAbs += e
+				sumSquares += e * e
+			}
+		}
+	}
+	n := float64(3 * c1.Width * c1.Height)
+	d.MeanError = sumAbs / n
+	d.PSNR = math.Inf(1)
+	if mse := sumSquares / n; mse > 0 {
+		d.PSNR = 10 * math.Log10(1/mse)
+	}
+	d.SSIM = ssim(c1, c2)
+	return d, nil
+}
+
+// ssim averages structural similarity of luminance over every window of the
+// canvases, clamping colors to [0, 1] range first
+func ssim(c1, c2 *Canvas) float64 {
+	const c1Const, c2Const = 0.01 * 0.01, 0.03 * 0.03
+	l1, l2 := luminanceMap(c1), luminanceMap(c2)
+	win := ssimWindow
+	if c1.Width < win {
+		win = c1.Width
+	}
+	if c1.Height < win {
+		win = c1.Height
+	}
+
+	total, windows := 0.0, 0
+	for y := 0; y+win <= c1.Height; y++ {
+		for x := 0; x+win <= c1.Width; x++ {
+			var mean1, mean2 float64
+			for j := y; j < y+win; j++ {
+				for i := x; i < x+win; i++ {
+					mean1 += l1[j][i]
+					mean2 += l2[j][i]
+				}
+			}
+			n := float64(win * win)
+			mean1 /= n
+			mean2 /= n
+			var var1, var2, cov float64
+			for j := y; j < y+win; j++ {
+				for i := x; i < x+win; i++ {
+					d1, d2 := l1[j][i]-mean1, l2[j][i]-mean2
+					var1 += d1 * d1
+					var2 += d2 * d2
+					cov += d1 * d2
+				}
+			}
+			var1 /= n
+			var2 /= n
+			cov /= n
+			total += ((2*mean1*mean2 + c1Const) * (2*cov + c2Const)) /
+				((mean1*mean1 + mean2*mean2 + c1Const) * (var1 + var2 + c2Const))
+			windows++
+		}
+	}
+	return total / float64(windows)
+}
+
+func luminanceMap(c *Canvas) [][]float64 {
+	result := make([][]float64, c.Height)
+	for h, row := range c.Colors {
+		result[h] = make([]float64, c.Width)
+		for w, col := range row {
+			result[h][w] = math.Min(math.Max(color.Luminance(col), 0), 1)
+		}
+	}
+	return result
+}
+
+// Diff returns a canvas that shows pixels of c1 dimmed to gray where the canvases
+// match and red pixels where any component differs by more than tolerance.
+// The number of mismatched pixels is returned as well.
+func Diff(c1, c2 *Canvas, tolerance float64) (*Canvas, int, error) {
+	if err := checkSameSize(c1, c2); err != nil {
+		return nil, 0, err
+	}
+	result := NewCanvas(c1.Width, c1.Height)
+	mismatched := 0
+	for h := 0; h < c1.Height; h++ {
+		for w := 0; w < c1.Width; w++ {
+			p1, p2 := c1.Colors[h][w], c2.Colors[h][w]
+			if math.Abs(p1.Red-p2.Red) > tolerance || math.Abs(p1.Green-p2.Green) > tolerance ||
+				math.Abs(p1.Blue-p2.Blue) > tolerance {
+				result.WritePixel(w, h, color.NewColor(1, 0, 0))
+				mismatched++
+				continue
+			}
+			l := 0.25 * math.Min(math.Max(color.Luminance(p1), 0), 1)
+			result.WritePixel(w, h, color.NewColor(l, l, l))
+		}
+	}
+	return result, mismatched, nil
+}
+
+func checkSameSize(c1, c2 *Canvas) error {
+	if c1.Width != c2.Width || c1.Height != c2.Height {
+		return errors.New("canvases have different dimensions")
+	}
+	if c1.Width == 0 || c1.Height == 0 {
+		return errors.New("canvases are empty")
+	}
+	return nil
+}
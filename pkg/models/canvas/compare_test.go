@@ -0,0 +1,140 @@
+package canvas
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func filledCanvas(w, h int, f func(w, h int) *color.Color) *Canvas {
+	c := NewCanvas(w, h)
+	for row := 0; row < h; row++ {
+		for col := 0; col < w; col++ {
+			c.WritePixel(col, row, f(col, row))
+		}
+	}
+	return c
+}
+
+func checkerboard(w, h int) *color.Color {
+	if (w+h)%2 == 0 {
+		return color.NewColor(1, 1, 1)
+	}
+	return color.NewColor(0, 0, 0)
+}
+
+func TestCompare(t *testing.T) {
+	a := filledCanvas(10, 10, checkerboard)
+	b := filledCanvas(10, 10, checkerboard)
+
+	d, err := Compare(a, b)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, d.MaxError)
+	assert.Equal(t, 0.0, d.MeanError)
+	assert.True(t, math.IsInf(d.PSNR, 1))
+	assert.True(t, util.FloatEquals(d.SSIM, 1))
+
+	b.WritePixel(3, 4, color.NewColor(0.5, 1, 1))
+	d, err = Compare(a, b)
+	assert.Nil(t, err)
+	assert.True(t, util.FloatEquals(d.MaxError, 1))
+	assert.True(t, util.FloatEquals(d.MeanError, 2.5/300))
+	assert.True(t, util.FloatEquals(d.PSNR, 10*math.Log10(300/2.25)))
+	assert.True(t, d.SSIM < 1)
+
+	inverted := filledCanvas(10, 10, func(w, h int) *color.Color { return checkerboard(w+1, h) })
+	d, err = Compare(a, inverted)
+	assert.Nil(t, err)
+	assert.True(t, d.SSIM < 0)
+
+	_, err = Compare(a, NewCanvas(10, 9))
+	assert.NotNil(t, err)
+	_, err = Compare(NewCanvas(0, 0), NewCanvas(0, 0))
+	assert.NotNil(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	a := filledCanvas(3, 1, func(w, h int) *color.Color { return color.NewColor(1, 1, 1) })
+	b := filledCanvas(3, 1, func(w, h int) *color.Color { return color.NewColor(1, 1, 1) })
+	b.WritePixel(1, 0, color.NewColor(1, 0.9, 1))
+	b.WritePixel(2, 0, color.NewColor(1, 1, 0.99))
+
+	diff, mismatched, err := Diff(a, b, 0.05)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, mismatched)
+	p, _ := diff.GetPixel(1, 0)
+	assert.True(t, color.Equals(p, color.NewColor(1, 0, 0)))
+	p, _ = diff.GetPixel(2, 0)
+	assert.True(t, color.Equals(p, color.NewColor(0.25, 0.25, 0.25)))
+
+	_, _, err = Diff(a, NewCanvas(1, 1), 0)
+	assert.NotNil(t, err)
+}
+
+func TestReadPPM(t *testing.T) {
+	c := filledCanvas(12, 3, func(w, h int) *color.Color {
+		return color.NewColor(float64(w)/11, float64(h)/2, 0.2)
+	})
+	var b bytes.Buffer
+	assert.Nil(t, writePPM(&b, c))
+
+	got, err := ReadPPM(&b)
+	assert.Nil(t, err)
+	d, err := Compare(c, got)
+	assert.Nil(t, err)
+	assert.True(t, d.MaxError <= 0.5/255)
+
+	tests := map[string]struct {
+		input string
+		want  []*color.Color
+		err   bool
+	}{
+		"comments":     {input: "P3 # plain\n# size\n2 1\n255\n255 0 0 # red\n0 0 255", want: []*color.Color{color.NewColor(1, 0, 0), color.NewColor(0, 0, 1)}},
+		"max value":    {input: "P3\n1 1\n15\n15 0 3\n", want: []*color.Color{color.NewColor(1, 0, 0.2)}},
+		"binary":       {input: "P6\n2 1\n255\n\xff\x00\x00\x00\x80\xff", want: []*color.Color{color.NewColor(1, 0, 0), color.NewColor(0, 128.0/255, 1)}},
+		"binary 16bit": {input: "P6 1 1 65535\n\xff\xff\x00\x00\x80\x00", want: []*color.Color{color.NewColor(1, 0, 32768.0/65535)}},
+		"wrong magic":  {input: "P5\n1 1\n255\n0", err: true},
+		"bad header":   {input: "P3\n1 x\n255\n0 0 0", err: true},
+		"missing data": {input: "P3\n2 1\n255\n0 0 0 0", err: true},
+		"empty":        {input: "", err: true},
+	}
+	for name, tc := range tests {
+		got, err := ReadPPM(strings.NewReader(tc.input))
+		if tc.err {
+			assert.NotNil(t, err, name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		for i, want := range tc.want {
+			p, _ := got.GetPixel(i, 0)
+			assert.True(t, color.Equals(p, want), "%s: expected %v, got %v", name, want, p)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	c := filledCanvas(4, 2, checkerboard)
+	dir := t.TempDir()
+	for _, file := range []string{"image.ppm", "image.png"} {
+		path := filepath.Join(dir, file)
+		if filepath.Ext(file) == ".ppm" {
+			assert.Nil(t, c.SaveToPPM(path))
+		} else {
+			assert.Nil(t, c.SaveToPNG(path))
+		}
+		got, err := Load(path)
+		assert.Nil(t, err, file)
+		assert.Equal(t, c.Colors, got.Colors, file)
+	}
+
+	_, err := Load(filepath.Join(dir, "image.jpg"))
+	assert.NotNil(t, err)
+	_, err = Load(filepath.Join(dir, "missing.png"))
+	assert.NotNil(t, err)
+}
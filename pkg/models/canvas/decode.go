@@ -0,0 +1,206 @@
+package canvas
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+// Load reads a .ppm, .pam or .png file into a canvas with colors in [0, 1] range
+func Load(file string) (*Canvas, error) {
+	handle, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".ppm":
+		return ReadPPM(handle)
+	case ".pam":
+		return ReadPAM(handle)
+	case ".png":
+		img, err := png.Decode(handle)
+		if err != nil {
+			return nil, err
+		}
+		return FromImage(img), nil
+	default:
+		return nil, fmt.Errorf("unsupported image format of %s", file)
+	}
+}
+
+// FromImage converts an image to a canvas with colors in [0, 1] range
+func FromImage(img image.Image) *Canvas {
+	b := img.Bounds()
+	c := NewCanvas(b.Dx(), b.Dy())
+	for h := 0; h < c.Height; h++ {
+		for w := 0; w < c.Width; w++ {
+			// RGBA returns colors already premultiplied by alpha
+			r, g, bl, a := img.At(b.Min.X+w, b.Min.Y+h).RGBA()
+			c.WritePixelAlpha(w, h, color.NewColor(float64(r)/0xffff, float64(g)/0xffff, float64(bl)/0xffff), float64(a)/0xffff)
+		}
+	}
+	return c
+}
+
+// ReadPAM reads a PAM image with RGB or RGB_ALPHA tuples into a canvas with
+// colors in [0, 1] range
+func ReadPAM(handle io.Reader) (*Canvas, error) {
+	r := bufio.NewReader(handle)
+	magic, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(magic) != pamMagicNumber {
+		return nil, errors.New("not a PAM image")
+	}
+
+	header := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] == "ENDHDR" {
+			break
+		}
+		header[fields[0]] = strings.Join(fields[1:], " ")
+	}
+	var dims [4]int
+	for i, key := range []string{"WIDTH", "HEIGHT", "DEPTH", "MAXVAL"} {
+		if dims[i], err = strconv.Atoi(header[key]); err != nil || dims[i] <= 0 {
+			return nil, fmt.Errorf("invalid PAM header value %s %q", key, header[key])
+		}
+	}
+	w, h, depth, maxValue := dims[0], dims[1], dims[2], dims[3]
+	if (depth != 3 && depth != 4) || maxValue > 65535 {
+		return nil, fmt.Errorf("unsupported PAM image with depth %d and maximum value %d", depth, maxValue)
+	}
+
+	c := NewCanvas(w, h)
+	sample := make([]byte, 1)
+	if maxValue > 255 {
+		sample = make([]byte, 2)
+	}
+	for row := 0; row < h; row++ {
+		for col := 0; col < w; col++ {
+			tuple := []float64{0, 0, 0, 1}
+			for i := 0; i < depth; i++ {
+				if _, err := io.ReadFull(r, sample); err != nil {
+					return nil, fmt.Errorf("can't read pixel [%d][%d]: %w", col, row, err)
+				}
+				v := int(sample[0])
+				if len(sample) == 2 {
+					v = v<<8 | int(sample[1])
+				}
+				tuple[i] = float64(v) / float64(maxValue)
+			}
+			alpha := tuple[3]
+			c.WritePixelAlpha(col, row, color.Premultiply(color.NewColor(tuple[0], tuple[1], tuple[2]), alpha), alpha)
+		}
+	}
+	return c, nil
+}
+
+// ReadPPM reads a plain (P3) or binary (P6) PPM image into a canvas with colors
+// in [0, 1] range
+func ReadPPM(handle io.Reader) (*Canvas, error) {
+	r := bufio.NewReader(handle)
+	magic, err := readPPMToken(r)
+	if err != nil {
+		return nil, err
+	}
+	if magic != ppmMagicNumber && magic != "P6" {
+		return nil, errors.New("not a PPM image")
+	}
+	var header [3]int
+	for i := range header {
+		token, err := readPPMToken(r)
+		if err != nil {
+			return nil, err
+		}
+		if header[i], err = strconv.Atoi(token); err != nil || header[i] <= 0 {
+			return nil, fmt.Errorf("invalid PPM header value %q", token)
+		}
+	}
+	w, h, maxValue := header[0], header[1], header[2]
+	if maxValue > 65535 {
+		return nil, fmt.Errorf("invalid PPM maximum color value %d", maxValue)
+	}
+
+	c := NewCanvas(w, h)
+	next := func() (int, error) {
+		token, err := readPPMToken(r)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.Atoi(token)
+	}
+	if magic == "P6" {
+		next = func() (int, error) {
+			hi, err := r.ReadByte()
+			if err != nil || maxValue < 256 {
+				return int(hi), err
+			}
+			lo, err := r.ReadByte()
+			return int(hi)<<8 | int(lo), err
+		}
+	}
+
+	for row := 0; row < h; row++ {
+		for col := 0; col < w; col++ {
+			var rgb [3]float64
+			for i := range rgb {
+				v, err := next()
+				if err != nil {
+					return nil, fmt.Errorf("can't read pixel [%d][%d]: %w", col, row, err)
+				}
+				rgb[i] = float64(v) / float64(maxValue)
+			}
+			c.WritePixel(col, row, color.NewColor(rgb[0], rgb[1], rgb[2]))
+		}
+	}
+	return c, nil
+}
+
+// readPPMToken reads the next whitespace separated token skipping comments. The
+// single whitespace after the token is consumed, which is where binary data of
+// P6 images starts after the header.
+func readPPMToken(r *bufio.Reader) (string, error) {
+	var token []byte
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF && len(token) > 0 {
+			return string(token), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case b == '#' && len(token) == 0:
+			if _, err := r.ReadString('\n'); err != nil {
+				return "", err
+			}
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r':
+			if len(token) > 0 {
+				return string(token), nil
+			}
+		default:
+			token = append(token, b)
+		}
+	}
+}
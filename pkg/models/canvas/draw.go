@@ -0,0 +1,193 @@
+package canvas
+
+import (
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+// Drawing functions are meant for debug overlays and annotations. Like
+// WritePixel, they silently skip pixels outside of the canvas, so shapes can be
+// partially or completely off screen.
+
+// DrawLine draws a one pixel wide line from [x0][y0] to [x1][y1] inclusive using
+// Bresenham's algorithm
+func (c *Canvas) DrawLine(x0, y0, x1, y1 int, col *color.Color) {
+	dx, dy := absInt(x1-x0), -absInt(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	err := dx + dy
+	for {
+		c.WritePixel(x0, y0, col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// DrawLineAA draws an antialiased line from (x0, y0) to (x1, y1) using Wu's
+// algorithm, where integer coordinates are centers of pixels. Partially covered
+// pixels are blended over the canvas.
+func (c *Canvas) DrawLineAA(x0, y0, x1, y1 float64, col *color.Color) {
+	steep := math.Abs(y1-y0) > math.Abs(x1-x0)
+	if steep {
+		x0, y0, x1, y1 = y0, x0, y1, x1
+	}
+	if x0 > x1 {
+		x0, y0, x1, y1 = x1, y1, x0, y0
+	}
+	plot := func(x, y int, coverage float64) {
+		if steep {
+			x, y = y, x
+		}
+		c.blendPixel(x, y, col, coverage)
+	}
+
+	gradient := 1.0
+	if dx := x1 - x0; dx != 0 {
+		gradient = (y1 - y0) / dx
+	}
+
+	// Endpoints only partially cover their pixels along the major axis
+	xEnd := math.Floor(x0 + 0.5)
+	yEnd := y0 + gradient*(xEnd-x0)
+	xGap := 1 - fractional(x0+0.5)
+	xStart := int(xEnd)
+	plot(xStart, int(math.Floor(yEnd)), (1-fractional(yEnd))*xGap)
+	plot(xStart, int(math.Floor(yEnd))+1, fractional(yEnd)*xGap)
+	y := yEnd + gradient
+
+	xEnd = math.Floor(x1 + 0.5)
+	yEnd = y1 + gradient*(xEnd-x1)
+	xGap = fractional(x1 + 0.5)
+	xStop := int(xEnd)
+	if xStop != xStart {
+		plot(xStop, int(math.Floor(yEnd)), (1-fractional(yEnd))*xGap)
+		plot(xStop, int(math.Floor(yEnd))+1, fractional(yEnd)*xGap)
+	}
+
+	for x := xStart + 1; x < xStop; x++ {
+		plot(x, int(math.Floor(y)), 1-fractional(y))
+		plot(x, int(math.Floor(y))+1, fractional(y))
+		y += gradient
+	}
+}
+
+// DrawRect draws a one pixel wide outline of rectangle w x h with the top left
+// corner at [x][y]
+func (c *Canvas) DrawRect(x, y, w, h int, col *color.Color) {
+	if w <= 0 || h <= 0 {
+		return
+	}
+	c.FillRect(x, y, w, 1, col)
+	c.FillRect(x, y+h-1, w, 1, col)
+	c.FillRect(x, y, 1, h, col)
+	c.FillRect(x+w-1, y, 1, h, col)
+}
+
+// FillRect fills rectangle w x h with the top left corner at [x][y]
+func (c *Canvas) FillRect(x, y, w, h int, col *color.Color) {
+	left, right := maxInt(x, 0), minInt(x+w, c.Width)
+	top, bottom := maxInt(y, 0), minInt(y+h, c.Height)
+	for row := top; row < bottom; row++ {
+		for column := left; column < right; column++ {
+			c.WritePixel(column, row, col)
+		}
+	}
+}
+
+// DrawCircle draws a one pixel wide circle of radius r around [cx][cy] using the
+// midpoint algorithm
+func (c *Canvas) DrawCircle(cx, cy, r int, col *color.Color) {
+	if r < 0 {
+		return
+	}
+	x, y, err := r, 0, 1-r
+	for x >= y {
+		for _, p := range [][2]int{{x, y}, {y, x}, {-y, x}, {-x, y}, {-x, -y}, {-y, -x}, {y, -x}, {x, -y}} {
+			c.WritePixel(cx+p[0], cy+p[1], col)
+		}
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+}
+
+// FillCircle fills a disk of radius r around [cx][cy] with the same outline as
+// DrawCircle
+func (c *Canvas) FillCircle(cx, cy, r int, col *color.Color) {
+	if r < 0 {
+		return
+	}
+	x, y, err := r, 0, 1-r
+	for x >= y {
+		c.FillRect(cx-x, cy+y, 2*x+1, 1, col)
+		c.FillRect(cx-x, cy-y, 2*x+1, 1, col)
+		c.FillRect(cx-y, cy+x, 2*y+1, 1, col)
+		c.FillRect(cx-y, cy-x, 2*y+1, 1, col)
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+}
+
+// blendPixel places col over the pixel at [w][h] with opacity coverage
+func (c *Canvas) blendPixel(w, h int, col *color.Color, coverage float64) {
+	if coverage <= 0 {
+		return
+	}
+	under, err := c.GetPixel(w, h)
+	if err != nil {
+		return
+	}
+	coverage = math.Min(coverage, 1)
+	result, alpha := color.Over(color.Scale(col, coverage), coverage, under, c.alpha(w, h))
+	c.WritePixelAlpha(w, h, result, alpha)
+}
+
+func fractional(x float64) float64 {
+	return x - math.Floor(x)
+}
+
+func absInt(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
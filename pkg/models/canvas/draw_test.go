@@ -0,0 +1,125 @@
+package canvas
+
+import (
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+// litPixels returns coordinates of every pixel that isn't black
+func litPixels(c *Canvas) [][2]int {
+	var result [][2]int
+	for h, row := range c.Colors {
+		for w, col := range row {
+			if !color.Equals(col, color.NewColor(0, 0, 0)) {
+				result = append(result, [2]int{w, h})
+			}
+		}
+	}
+	return result
+}
+
+func TestDrawLine(t *testing.T) {
+	white := color.NewColor(1, 1, 1)
+	tests := map[string]struct {
+		x0, y0, x1, y1 int
+		want           [][2]int
+	}{
+		"shallow":  {0, 0, 3, 1, [][2]int{{0, 0}, {1, 0}, {2, 1}, {3, 1}}},
+		"reversed": {3, 1, 0, 0, [][2]int{{0, 0}, {1, 0}, {2, 1}, {3, 1}}},
+		"steep":    {1, 0, 2, 3, [][2]int{{1, 0}, {1, 1}, {2, 2}, {2, 3}}},
+		"point":    {3, 3, 3, 3, [][2]int{{3, 3}}},
+		"clipped":  {-2, 1, 2, 1, [][2]int{{0, 1}, {1, 1}, {2, 1}}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := NewCanvas(5, 4)
+			c.DrawLine(tc.x0, tc.y0, tc.x1, tc.y1, white)
+			assert.ElementsMatch(t, tc.want, litPixels(c))
+		})
+	}
+}
+
+func TestDrawLineAA(t *testing.T) {
+	c := NewTransparentCanvas(6, 3)
+	c.DrawLineAA(1, 0.5, 4, 0.5, color.NewColor(1, 0, 0))
+
+	// The line runs between two rows, so both are half covered
+	for _, p := range [][2]int{{2, 0}, {2, 1}, {3, 0}, {3, 1}} {
+		a, _ := c.GetAlpha(p[0], p[1])
+		assert.InDelta(t, 0.5, a, 1e-9, "pixel %v", p)
+		assertPixel(t, c, p[0], p[1], color.NewColor(0.5, 0, 0))
+	}
+	a, _ := c.GetAlpha(1, 0)
+	assert.InDelta(t, 0.25, a, 1e-9)
+	for _, p := range [][2]int{{0, 0}, {5, 0}, {2, 2}} {
+		a, _ := c.GetAlpha(p[0], p[1])
+		assert.Equal(t, 0.0, a, "pixel %v", p)
+	}
+
+	// Lines outside of the canvas are ignored
+	c.DrawLineAA(-10, -10, -5, -20, color.NewColor(1, 0, 0))
+}
+
+func TestRectangles(t *testing.T) {
+	white := color.NewColor(1, 1, 1)
+	c := NewCanvas(5, 5)
+	c.DrawRect(1, 1, 3, 3, white)
+	assert.Len(t, litPixels(c), 8)
+	assertPixel(t, c, 2, 2, color.NewColor(0, 0, 0))
+
+	c = NewCanvas(5, 5)
+	c.FillRect(-2, 3, 4, 10, white)
+	assert.ElementsMatch(t, [][2]int{{0, 3}, {1, 3}, {0, 4}, {1, 4}}, litPixels(c))
+}
+
+func TestCircles(t *testing.T) {
+	white := color.NewColor(1, 1, 1)
+	c := NewCanvas(11, 11)
+	c.DrawCircle(5, 5, 4, white)
+	for _, p := range [][2]int{{1, 5}, {9, 5}, {5, 1}, {5, 9}} {
+		assertPixel(t, c, p[0], p[1], white)
+	}
+	assertPixel(t, c, 5, 5, color.NewColor(0, 0, 0))
+	// Every pixel of the outline is at roughly the same distance from the center
+	for _, p := range litPixels(c) {
+		d := (p[0]-5)*(p[0]-5) + (p[1]-5)*(p[1]-5)
+		assert.True(t, d >= 9 && d <= 25, "pixel %v", p)
+	}
+
+	filled := NewCanvas(11, 11)
+	filled.FillCircle(5, 5, 4, white)
+	for _, p := range litPixels(c) {
+		assertPixel(t, filled, p[0], p[1], white)
+	}
+	assertPixel(t, filled, 5, 5, white)
+	assertPixel(t, filled, 1, 1, color.NewColor(0, 0, 0))
+
+	// Clipped circles don't panic
+	c.FillCircle(0, 0, 20, white)
+	assertPixel(t, c, 10, 10, white)
+}
+
+func TestDrawText(t *testing.T) {
+	white := color.NewColor(1, 1, 1)
+	c := NewCanvas(12, 16)
+	c.DrawText(0, 0, "1i\n-", white)
+
+	// Stem of "1"
+	for y := 0; y < GlyphHeight; y++ {
+		assertPixel(t, c, 2, y, white)
+	}
+	// Lower case "i" is drawn as "I" in the next cell
+	assertPixel(t, c, 6+2, 3, white)
+	assertPixel(t, c, 6+0, 3, color.NewColor(0, 0, 0))
+	// "-" is on the second line
+	for x := 0; x < GlyphWidth; x++ {
+		assertPixel(t, c, x, GlyphHeight+1+3, white)
+	}
+	assert.Len(t, litPixels(c), 10+11+5)
+
+	w, h := TextSize("1i\n-")
+	assert.Equal(t, 11, w)
+	assert.Equal(t, 15, h)
+}
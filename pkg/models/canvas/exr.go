@@ -0,0 +1,256 @@
+package canvas
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+const (
+	exrMagicNumber = 20000630
+	exrVersion     = 2
+	// exrLongNames flag allows attribute and channel names longer than 31 bytes
+	exrLongNames  = 0x400
+	exrPixelFloat = 2
+)
+
+// EXRCompression selects how pixel data of OpenEXR files is compressed
+type EXRCompression int
+
+const (
+	// EXRNoCompression stores raw pixel data
+	EXRNoCompression EXRCompression = iota
+	// EXRZip losslessly compresses blocks of 16 scanlines with zlib
+	EXRZip
+)
+
+// exrCompressionIDs are the values OpenEXR uses for every compression method
+var exrCompressionIDs = map[EXRCompression]uint8{
+	EXRNoCompression: 0,
+	EXRZip:           3,
+}
+
+// exrLinesPerChunk is the number of scanlines compressed together
+var exrLinesPerChunk = map[EXRCompression]int{
+	EXRNoCompression: 1,
+	EXRZip:           16,
+}
+
+// SaveToEXR saves canvas to a ZIP compressed OpenEXR file. Unlike the other
+// formats it keeps colors as 32-bit floats, so values outside of [0, 1] survive
+// and no tone mapping is needed.
+func (c *Canvas) SaveToEXR(file string) error {
+	return SaveLayersToEXR([]Layer{{Canvas: c}}, file, EXRZip)
+}
+
+// SaveLayersToEXR saves layers of the same dimensions to a single scanline
+// OpenEXR file. Every layer is stored as R, G, B and A channels prefixed by the
+// name of the layer, e.g. depth.R, except for the layer with an empty name which
+// is the main image. Colors stay premultiplied by alpha, as OpenEXR expects.
+func SaveLayersToEXR(layers []Layer, file string, compression EXRCompression) error {
+	handle, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := writeEXR(handle, layers, compression); err != nil {
+		handle.Close()
+		return err
+	}
+	return handle.Close()
+}
+
+// exrChannel is a single component of one of the layers
+type exrChannel struct {
+	name      string
+	canvas    *Canvas
+	component int
+}
+
+func (ch exrChannel) value(x, y int) float64 {
+	col := ch.canvas.Colors[y][x]
+	switch ch.component {
+	case 0:
+		return col.Red
+	case 1:
+		return col.Green
+	case 2:
+		return col.Blue
+	}
+	return ch.canvas.alpha(x, y)
+}
+
+// exrChannels returns channels of every layer sorted by name, which is the order
+// OpenEXR requires both in the header and in pixel data
+func exrChannels(layers []Layer) ([]exrChannel, error) {
+	if len(layers) == 0 {
+		return nil, errors.New("no layers to save")
+	}
+	w, h := layers[0].Canvas.Width, layers[0].Canvas.Height
+	if w <= 0 || h <= 0 {
+		return nil, errors.New("image dimensions must be positive")
+	}
+	var channels []exrChannel
+	names := make(map[string]bool)
+	for _, l := range layers {
+		if l.Canvas.Width != w || l.Canvas.Height != h {
+			return nil, fmt.Errorf("layer %q has different dimensions", l.Name)
+		}
+		if names[l.Name] {
+			return nil, fmt.Errorf("layer %q is saved more than once", l.Name)
+		}
+		names[l.Name] = true
+		prefix := ""
+		if l.Name != "" {
+			prefix = l.Name + "."
+		}
+		for component, name := range []string{"R", "G", "B", "A"} {
+			channels = append(channels, exrChannel{name: prefix + name, canvas: l.Canvas, component: component})
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].name < channels[j].name })
+	return channels, nil
+}
+
+func writeEXR(handle io.Writer, layers []Layer, compression EXRCompression) error {
+	linesPerChunk, ok := exrLinesPerChunk[compression]
+	if !ok {
+		return fmt.Errorf("unknown compression %d", compression)
+	}
+	channels, err := exrChannels(layers)
+	if err != nil {
+		return err
+	}
+	w, h := layers[0].Canvas.Width, layers[0].Canvas.Height
+
+	header := exrHeader(channels, w, h, compression)
+	var chunks [][]byte
+	for y := 0; y < h; y += linesPerChunk {
+		data := exrScanlines(channels, w, y, minInt(y+linesPerChunk, h))
+		if compression == EXRZip {
+			if data, err = exrZip(data); err != nil {
+				return err
+			}
+		}
+		chunks = append(chunks, data)
+	}
+
+	out := bufio.NewWriter(handle)
+	if _, err := out.Write(header); err != nil {
+		return err
+	}
+	// Offset table points to the start of every chunk in the file
+	offset := uint64(len(header) + 8*len(chunks))
+	for _, chunk := range chunks {
+		if err := binary.Write(out, binary.LittleEndian, offset); err != nil {
+			return err
+		}
+		offset += uint64(8 + len(chunk))
+	}
+	for i, chunk := range chunks {
+		if err := binary.Write(out, binary.LittleEndian, [2]int32{int32(i * linesPerChunk), int32(len(chunk))}); err != nil {
+			return err
+		}
+		if _, err := out.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+func exrHeader(channels []exrChannel, w, h int, compression EXRCompression) []byte {
+	var b bytes.Buffer
+	flags := uint32(exrVersion)
+	var chlist bytes.Buffer
+	for _, ch := range channels {
+		if len(ch.name) > 31 {
+			flags |= exrLongNames
+		}
+		chlist.WriteString(ch.name)
+		chlist.WriteByte(0)
+		// Pixel type, linear flag with 3 reserved bytes, x and y sampling
+		binary.Write(&chlist, binary.LittleEndian, [4]int32{exrPixelFloat, 0, 1, 1})
+	}
+	chlist.WriteByte(0)
+
+	binary.Write(&b, binary.LittleEndian, [2]uint32{exrMagicNumber, flags})
+	window := exrValue([4]int32{0, 0, int32(w - 1), int32(h - 1)})
+	writeEXRAttribute(&b, "channels", "chlist", chlist.Bytes())
+	writeEXRAttribute(&b, "compression", "compression", []byte{exrCompressionIDs[compression]})
+	writeEXRAttribute(&b, "dataWindow", "box2i", window)
+	writeEXRAttribute(&b, "displayWindow", "box2i", window)
+	writeEXRAttribute(&b, "lineOrder", "lineOrder", []byte{0})
+	writeEXRAttribute(&b, "pixelAspectRatio", "float", exrValue(float32(1)))
+	writeEXRAttribute(&b, "screenWindowCenter", "v2f", exrValue([2]float32{0, 0}))
+	writeEXRAttribute(&b, "screenWindowWidth", "float", exrValue(float32(1)))
+	b.WriteByte(0)
+	return b.Bytes()
+}
+
+func writeEXRAttribute(b *bytes.Buffer, name, kind string, value []byte) {
+	b.WriteString(name)
+	b.WriteByte(0)
+	b.WriteString(kind)
+	b.WriteByte(0)
+	binary.Write(b, binary.LittleEndian, int32(len(value)))
+	b.Write(value)
+}
+
+func exrValue(v interface{}) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, v)
+	return b.Bytes()
+}
+
+// exrScanlines returns pixel data of rows from top to bottom exclusive, every row
+// stores all of its values of the first channel, then of the second one etc.
+func exrScanlines(channels []exrChannel, w, top, bottom int) []byte {
+	data := make([]byte, 0, (bottom-top)*len(channels)*w*4)
+	var value [4]byte
+	for y := top; y < bottom; y++ {
+		for _, ch := range channels {
+			for x := 0; x < w; x++ {
+				binary.LittleEndian.PutUint32(value[:], math.Float32bits(float32(ch.value(x, y))))
+				data = append(data, value[:]...)
+			}
+		}
+	}
+	return data
+}
+
+// exrZip compresses data the way OpenEXR does: bytes are split into two halves
+// of even and odd bytes and delta encoded before deflating, which makes floats
+// compress much better. Data that doesn't get smaller is stored uncompressed.
+func exrZip(data []byte) ([]byte, error) {
+	tmp := make([]byte, len(data))
+	half := (len(data) + 1) / 2
+	for i, v := range data {
+		if i%2 == 0 {
+			tmp[i/2] = v
+		} else {
+			tmp[half+i/2] = v
+		}
+	}
+	for i := len(tmp) - 1; i > 0; i-- {
+		tmp[i] = tmp[i] - tmp[i-1] + 128
+	}
+
+	var b bytes.Buffer
+	zw := zlib.NewWriter(&b)
+	if _, err := zw.Write(tmp); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if b.Len() >= len(data) {
+		return data, nil
+	}
+	return b.Bytes(), nil
+}
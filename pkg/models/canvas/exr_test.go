@@ -0,0 +1,164 @@
+package canvas
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeEXR reads files written by writeEXR and returns their attributes and
+// values of every channel in row major order
+func decodeEXR(t *testing.T, data []byte) (map[string][]byte, []string, map[string][]float32) {
+	t.Helper()
+	r := bytes.NewReader(data)
+	var start [2]uint32
+	assert.Nil(t, binary.Read(r, binary.LittleEndian, &start))
+	assert.Equal(t, uint32(exrMagicNumber), start[0])
+	assert.Equal(t, uint32(exrVersion), start[1]&0xff)
+
+	readString := func(r *bytes.Reader) string {
+		var s []byte
+		for {
+			b, err := r.ReadByte()
+			assert.Nil(t, err)
+			if b == 0 {
+				return string(s)
+			}
+			s = append(s, b)
+		}
+	}
+	attributes := make(map[string][]byte)
+	for name := readString(r); name != ""; name = readString(r) {
+		readString(r)
+		var size int32
+		assert.Nil(t, binary.Read(r, binary.LittleEndian, &size))
+		value := make([]byte, size)
+		_, err := io.ReadFull(r, value)
+		assert.Nil(t, err)
+		attributes[name] = value
+	}
+
+	var names []string
+	chlist := bytes.NewReader(attributes["channels"])
+	for name := readString(chlist); name != ""; name = readString(chlist) {
+		names = append(names, name)
+		var rest [4]int32
+		assert.Nil(t, binary.Read(chlist, binary.LittleEndian, &rest))
+		assert.Equal(t, [4]int32{exrPixelFloat, 0, 1, 1}, rest)
+	}
+
+	var window [4]int32
+	assert.Nil(t, binary.Read(bytes.NewReader(attributes["dataWindow"]), binary.LittleEndian, &window))
+	w, h := int(window[2]+1), int(window[3]+1)
+	linesPerChunk := 1
+	if attributes["compression"][0] == 3 {
+		linesPerChunk = 16
+	}
+	offsets := make([]uint64, (h+linesPerChunk-1)/linesPerChunk)
+	assert.Nil(t, binary.Read(r, binary.LittleEndian, offsets))
+
+	values := make(map[string][]float32)
+	for i, offset := range offsets {
+		r.Seek(int64(offset), io.SeekStart)
+		var chunk [2]int32
+		assert.Nil(t, binary.Read(r, binary.LittleEndian, &chunk))
+		assert.Equal(t, int32(i*linesPerChunk), chunk[0])
+		pixels := make([]byte, chunk[1])
+		_, err := io.ReadFull(r, pixels)
+		assert.Nil(t, err)
+
+		lines := minInt(linesPerChunk, h-i*linesPerChunk)
+		if size := lines * len(names) * w * 4; len(pixels) < size {
+			pixels = unzipEXR(t, pixels, size)
+		}
+		pr := bytes.NewReader(pixels)
+		for y := 0; y < lines; y++ {
+			for _, name := range names {
+				row := make([]float32, w)
+				assert.Nil(t, binary.Read(pr, binary.LittleEndian, row))
+				values[name] = append(values[name], row...)
+			}
+		}
+	}
+	return attributes, names, values
+}
+
+func unzipEXR(t *testing.T, data []byte, size int) []byte {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	assert.Nil(t, err)
+	tmp, err := ioutil.ReadAll(zr)
+	assert.Nil(t, err)
+	assert.Len(t, tmp, size)
+	for i := 1; i < len(tmp); i++ {
+		tmp[i] = tmp[i-1] + tmp[i] - 128
+	}
+	result := make([]byte, len(tmp))
+	half := (len(tmp) + 1) / 2
+	for i := range result {
+		if i%2 == 0 {
+			result[i] = tmp[i/2]
+		} else {
+			result[i] = tmp[half+i/2]
+		}
+	}
+	return result
+}
+
+func TestSaveLayersToEXR(t *testing.T) {
+	beauty := filledCanvas(3, 20, func(w, h int) *color.Color {
+		return color.NewColor(float64(w)*10, -float64(h), 0.5)
+	})
+	beauty.WritePixelAlpha(1, 2, color.NewColor(0.25, 0, 0), 0.25)
+	depth := filledCanvas(3, 20, func(w, h int) *color.Color {
+		d := float64(w + h*3)
+		return color.NewColor(d, d, d)
+	})
+
+	for name, compression := range map[string]EXRCompression{"none": EXRNoCompression, "zip": EXRZip} {
+		t.Run(name, func(t *testing.T) {
+			var b bytes.Buffer
+			assert.Nil(t, writeEXR(&b, []Layer{{Name: "depth", Canvas: depth}, {Canvas: beauty}}, compression))
+			attributes, names, values := decodeEXR(t, b.Bytes())
+
+			assert.Equal(t, []string{"A", "B", "G", "R", "depth.A", "depth.B", "depth.G", "depth.R"}, names)
+			assert.Equal(t, exrValue([4]int32{0, 0, 2, 19}), attributes["displayWindow"])
+			for _, required := range []string{"lineOrder", "pixelAspectRatio", "screenWindowCenter", "screenWindowWidth"} {
+				assert.Contains(t, attributes, required)
+			}
+			// Values outside of [0, 1] are kept as they are
+			assert.Equal(t, float32(20), values["R"][2])
+			assert.Equal(t, float32(-19), values["G"][19*3])
+			assert.Equal(t, float32(0.25), values["R"][2*3+1])
+			assert.Equal(t, float32(0.25), values["A"][2*3+1])
+			assert.Equal(t, float32(1), values["A"][0])
+			assert.Equal(t, float32(59), values["depth.B"][59])
+		})
+	}
+}
+
+func TestEXRZipIsSmaller(t *testing.T) {
+	c := NewCanvas(64, 64)
+	var raw, zipped bytes.Buffer
+	assert.Nil(t, writeEXR(&raw, []Layer{{Canvas: c}}, EXRNoCompression))
+	assert.Nil(t, writeEXR(&zipped, []Layer{{Canvas: c}}, EXRZip))
+	assert.True(t, zipped.Len() < raw.Len()/10, "%d vs %d bytes", zipped.Len(), raw.Len())
+}
+
+func TestSaveLayersToEXRErrors(t *testing.T) {
+	tests := map[string][]Layer{
+		"no layers":            nil,
+		"empty canvas":         {{Canvas: NewCanvas(0, 0)}},
+		"different dimensions": {{Canvas: NewCanvas(2, 2)}, {Name: "depth", Canvas: NewCanvas(2, 3)}},
+		"duplicate names":      {{Name: "id", Canvas: NewCanvas(2, 2)}, {Name: "id", Canvas: NewCanvas(2, 2)}},
+	}
+	for name, layers := range tests {
+		assert.NotNil(t, writeEXR(ioutil.Discard, layers, EXRZip), name)
+	}
+	assert.NotNil(t, writeEXR(ioutil.Discard, []Layer{{Canvas: NewCanvas(1, 1)}}, EXRCompression(42)))
+}
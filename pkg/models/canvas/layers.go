@@ -0,0 +1,32 @@
+package canvas
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Layer is a named canvas that belongs to a bigger image, e.g. one of render
+// passes stored next to the final image
+type Layer struct {
+	Name   string
+	Canvas *Canvas
+}
+
+// LayerPath returns the file a layer is saved to by SaveLayers, which is file
+// with the name of the layer inserted before the extension, e.g. out.depth.png
+func LayerPath(file, name string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + name + ext
+}
+
+// SaveLayers saves every layer to a separate file named by LayerPath using save,
+// e.g. (*Canvas).SaveToPNG
+func SaveLayers(layers []Layer, file string, save func(*Canvas, string) error) error {
+	for _, l := range layers {
+		if err := save(l.Canvas, LayerPath(file, l.Name)); err != nil {
+			return fmt.Errorf("saving layer %s: %w", l.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package canvas
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayerPath(t *testing.T) {
+	tests := map[string]struct {
+		file, name, want string
+	}{
+		"extension":  {file: "out.png", name: "depth", want: "out.depth.png"},
+		"directory":  {file: filepath.Join("dir.v2", "out"), name: "id", want: filepath.Join("dir.v2", "out.id")},
+		"double ext": {file: "out.tar.ppm", name: "normal", want: "out.tar.normal.ppm"},
+	}
+	for name, tc := range tests {
+		assert.Equal(t, tc.want, LayerPath(tc.file, tc.name), name)
+	}
+}
+
+func TestSaveLayers(t *testing.T) {
+	dir := t.TempDir()
+	layers := []Layer{{Name: "depth", Canvas: NewCanvas(2, 1)}, {Name: "albedo", Canvas: NewCanvas(1, 2)}}
+	assert.Nil(t, SaveLayers(layers, filepath.Join(dir, "out.png"), (*Canvas).SaveToPNG))
+
+	c, err := Load(filepath.Join(dir, "out.depth.png"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, c.Width)
+	c, err = Load(filepath.Join(dir, "out.albedo.png"))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, c.Height)
+
+	err = SaveLayers(layers, "out.png", func(*Canvas, string) error { return errors.New("disk full") })
+	assert.EqualError(t, err, "saving layer depth: disk full")
+}
@@ -0,0 +1,209 @@
+package canvas
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+// ResampleFilter selects how pixels are interpolated when a canvas is resized
+type ResampleFilter int
+
+const (
+	// Nearest takes the closest source pixel, which keeps hard edges
+	Nearest ResampleFilter = iota
+	// Bilinear interpolates linearly between neighbouring pixels
+	Bilinear
+	// Lanczos uses the Lanczos kernel with 3 lobes, which keeps the image sharp
+	// but can produce slight ringing around hard edges
+	Lanczos
+)
+
+// Crop returns a new canvas with the w x h area of c starting at [x][y]
+func (c *Canvas) Crop(x, y, w, h int) (*Canvas, error) {
+	if w <= 0 || h <= 0 || x < 0 || y < 0 || x+w > c.Width || y+h > c.Height {
+		return nil, errors.New("crop area is outside of canvas")
+	}
+	result := NewCanvas(w, h)
+	for row := 0; row < h; row++ {
+		copy(result.Colors[row], c.Colors[y+row][x:x+w])
+		copy(result.Alpha[row], c.alphaRow(y + row)[x:x+w])
+	}
+	return result, nil
+}
+
+// FlipHorizontal returns a new canvas mirrored left to right
+func (c *Canvas) FlipHorizontal() *Canvas {
+	result := NewCanvas(c.Width, c.Height)
+	for h, row := range c.Colors {
+		for w, col := range row {
+			result.Colors[h][c.Width-1-w] = col
+			result.Alpha[h][c.Width-1-w] = c.alpha(w, h)
+		}
+	}
+	return result
+}
+
+// FlipVertical returns a new canvas mirrored top to bottom
+func (c *Canvas) FlipVertical() *Canvas {
+	result := NewCanvas(c.Width, c.Height)
+	for h, row := range c.Colors {
+		copy(result.Colors[c.Height-1-h], row)
+		copy(result.Alpha[c.Height-1-h], c.alphaRow(h))
+	}
+	return result
+}
+
+// Blit copies all of src including its alpha into c with the top left corner of
+// src at [x][y]. Parts of src that fall outside of c are clipped.
+func (c *Canvas) Blit(src *Canvas, x, y int) {
+	for h, row := range src.Colors {
+		for w, col := range row {
+			c.WritePixelAlpha(x+w, y+h, col, src.alpha(w, h))
+		}
+	}
+}
+
+// Composite places src over c with the top left corner of src at [x][y] like
+// Blit, but blends the pixels using the "over" operator. Alpha of src is scaled
+// by opacity between 0 and 1, so opacity of 1 uses src alpha as it is.
+func (c *Canvas) Composite(src *Canvas, x, y int, opacity float64) {
+	opacity = math.Min(math.Max(opacity, 0), 1)
+	for h, row := range src.Colors {
+		for w, col := range row {
+			under, err := c.GetPixel(x+w, y+h)
+			if err != nil {
+				continue
+			}
+			result, alpha := color.Over(color.Scale(col, opacity), src.alpha(w, h)*opacity, under, c.alpha(x+w, y+h))
+			c.WritePixelAlpha(x+w, y+h, result, alpha)
+		}
+	}
+}
+
+// Resize returns a new canvas of dimensions w x h with c scaled to fit it
+func (c *Canvas) Resize(w, h int, filter ResampleFilter) (*Canvas, error) {
+	if w <= 0 || h <= 0 {
+		return nil, errors.New("canvas dimensions must be positive")
+	}
+	if c.Width == 0 || c.Height == 0 {
+		return nil, errors.New("can't resize empty canvas")
+	}
+
+	switch filter {
+	case Nearest:
+		return c.resizeNearest(w, h), nil
+	case Bilinear:
+		return c.resizeSeparable(w, h, 1, triangle), nil
+	case Lanczos:
+		return c.resizeSeparable(w, h, 3, lanczos3), nil
+	default:
+		return nil, errors.New("unknown resample filter")
+	}
+}
+
+func (c *Canvas) resizeNearest(w, h int) *Canvas {
+	result := NewCanvas(w, h)
+	for row := 0; row < h; row++ {
+		srcRow := (2*row + 1) * c.Height / (2 * h)
+		for col := 0; col < w; col++ {
+			srcCol := (2*col + 1) * c.Width / (2 * w)
+			result.Colors[row][col] = c.Colors[srcRow][srcCol]
+			result.Alpha[row][col] = c.alpha(srcCol, srcRow)
+		}
+	}
+	return result
+}
+
+// resizeSeparable resamples rows first and then columns with a kernel of the
+// given radius. When shrinking, the kernel is stretched so that every source
+// pixel contributes to the result. Colors are premultiplied, so they are filtered
+// together with alpha the same way.
+func (c *Canvas) resizeSeparable(w, h int, radius float64, kernel func(float64) float64) *Canvas {
+	horizontal := resampleWeights(c.Width, w, radius, kernel)
+	tmp := NewCanvas(w, c.Height)
+	for row := 0; row < c.Height; row++ {
+		alpha := c.alphaRow(row)
+		for col := 0; col < w; col++ {
+			tmp.Colors[row][col], tmp.Alpha[row][col] = weightedSum(horizontal[col], c.Colors[row], alpha)
+		}
+	}
+
+	vertical := resampleWeights(c.Height, h, radius, kernel)
+	result := NewCanvas(w, h)
+	column := make([]*color.Color, c.Height)
+	columnAlpha := make([]float64, c.Height)
+	for col := 0; col < w; col++ {
+		for row := 0; row < c.Height; row++ {
+			column[row], columnAlpha[row] = tmp.Colors[row][col], tmp.Alpha[row][col]
+		}
+		for row := 0; row < h; row++ {
+			result.Colors[row][col], result.Alpha[row][col] = weightedSum(vertical[row], column, columnAlpha)
+		}
+	}
+	return result
+}
+
+type weight struct {
+	index int
+	value float64
+}
+
+// resampleWeights returns, for every destination pixel, the source pixels that
+// contribute to it with normalized weights
+func resampleWeights(srcSize, dstSize int, radius float64, kernel func(float64) float64) [][]weight {
+	scale := float64(srcSize) / float64(dstSize)
+	filterScale := math.Max(scale, 1)
+	support := radius * filterScale
+
+	result := make([][]weight, dstSize)
+	for i := range result {
+		center := (float64(i)+0.5)*scale - 0.5
+		total := 0.0
+		for j := int(math.Ceil(center - support)); j <= int(math.Floor(center+support)); j++ {
+			v := kernel((float64(j) - center) / filterScale)
+			if v == 0 {
+				continue
+			}
+			// Pixels beyond the edges repeat the edge pixel
+			idx := j
+			if idx < 0 {
+				idx = 0
+			} else if idx >= srcSize {
+				idx = srcSize - 1
+			}
+			result[i] = append(result[i], weight{idx, v})
+			total += v
+		}
+		for k := range result[i] {
+			result[i][k].value /= total
+		}
+	}
+	return result
+}
+
+func weightedSum(weights []weight, colors []*color.Color, alpha []float64) (*color.Color, float64) {
+	result := color.NewColor(0, 0, 0)
+	resultAlpha := 0.0
+	for _, w := range weights {
+		result = color.Add(result, color.Scale(colors[w.index], w.value))
+		resultAlpha += alpha[w.index] * w.value
+	}
+	return result, resultAlpha
+}
+
+func triangle(x float64) float64 {
+	return math.Max(1-math.Abs(x), 0)
+}
+
+func lanczos3(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	if math.Abs(x) >= 3 {
+		return 0
+	}
+	px := math.Pi * x
+	return 3 * math.Sin(px) * math.Sin(px/3) / (px * px)
+}
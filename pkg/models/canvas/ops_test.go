@@ -0,0 +1,146 @@
+package canvas
+
+import (
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+// numbered fills every pixel with a unique color so that moved pixels can be traced
+func numbered(w, h int) *Canvas {
+	return filledCanvas(w, h, func(col, row int) *color.Color {
+		return color.NewColor(float64(col), float64(row), 0)
+	})
+}
+
+func assertPixel(t *testing.T, c *Canvas, w, h int, want *color.Color) {
+	t.Helper()
+	got, err := c.GetPixel(w, h)
+	assert.Nil(t, err)
+	if !color.Equals(got, want) {
+		t.Fatalf("pixel [%d][%d]: expected %v, got %v", w, h, want, got)
+	}
+}
+
+func TestCrop(t *testing.T) {
+	c := numbered(5, 4)
+	cropped, err := c.Crop(1, 2, 3, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, cropped.Width)
+	assert.Equal(t, 2, cropped.Height)
+	assertPixel(t, cropped, 0, 0, color.NewColor(1, 2, 0))
+	assertPixel(t, cropped, 2, 1, color.NewColor(3, 3, 0))
+
+	for _, area := range [][4]int{{-1, 0, 2, 2}, {4, 0, 2, 2}, {0, 3, 1, 2}, {0, 0, 0, 1}} {
+		_, err := c.Crop(area[0], area[1], area[2], area[3])
+		assert.NotNil(t, err, "%v", area)
+	}
+}
+
+func TestFlip(t *testing.T) {
+	c := numbered(3, 2)
+	h := c.FlipHorizontal()
+	assertPixel(t, h, 0, 0, color.NewColor(2, 0, 0))
+	assertPixel(t, h, 2, 1, color.NewColor(0, 1, 0))
+	v := c.FlipVertical()
+	assertPixel(t, v, 0, 0, color.NewColor(0, 1, 0))
+	assertPixel(t, v, 2, 1, color.NewColor(2, 0, 0))
+	// Source canvas is not modified
+	assertPixel(t, c, 0, 0, color.NewColor(0, 0, 0))
+}
+
+func TestBlit(t *testing.T) {
+	dst := NewCanvas(4, 4)
+	src := numbered(2, 2)
+	dst.Blit(src, 1, 1)
+	assertPixel(t, dst, 0, 0, color.NewColor(0, 0, 0))
+	assertPixel(t, dst, 1, 1, color.NewColor(0, 0, 0))
+	assertPixel(t, dst, 2, 2, color.NewColor(1, 1, 0))
+
+	// Clipped on every side
+	dst.Blit(numbered(6, 6), -1, -1)
+	assertPixel(t, dst, 0, 0, color.NewColor(1, 1, 0))
+	assertPixel(t, dst, 3, 3, color.NewColor(4, 4, 0))
+}
+
+func TestComposite(t *testing.T) {
+	dst := filledCanvas(3, 1, func(w, h int) *color.Color { return color.NewColor(0, 0, 1) })
+	src := filledCanvas(2, 1, func(w, h int) *color.Color { return color.NewColor(1, 0, 0) })
+
+	dst.Composite(src, 1, 0, 0.25)
+	assertPixel(t, dst, 0, 0, color.NewColor(0, 0, 1))
+	assertPixel(t, dst, 1, 0, color.NewColor(0.25, 0, 0.75))
+	assertPixel(t, dst, 2, 0, color.NewColor(0.25, 0, 0.75))
+
+	dst.Composite(src, -1, 0, 2)
+	assertPixel(t, dst, 0, 0, color.NewColor(1, 0, 0))
+}
+
+func TestCompositeAlpha(t *testing.T) {
+	dst := NewTransparentCanvas(2, 1)
+	dst.WritePixel(1, 0, color.NewColor(0, 0, 1))
+	src := NewTransparentCanvas(2, 1)
+	src.WritePixelAlpha(0, 0, color.NewColor(0.5, 0, 0), 0.5)
+	src.WritePixelAlpha(1, 0, color.NewColor(0.5, 0, 0), 0.5)
+
+	dst.Composite(src, 0, 0, 1)
+	assertPixel(t, dst, 0, 0, color.NewColor(0.5, 0, 0))
+	assertPixel(t, dst, 1, 0, color.NewColor(0.5, 0, 0.5))
+	a, _ := dst.GetAlpha(0, 0)
+	assert.InDelta(t, 0.5, a, 1e-9)
+	a, _ = dst.GetAlpha(1, 0)
+	assert.InDelta(t, 1, a, 1e-9)
+}
+
+func TestResize(t *testing.T) {
+	c := numbered(4, 2)
+
+	nearest, err := c.Resize(8, 4, Nearest)
+	assert.Nil(t, err)
+	assertPixel(t, nearest, 0, 0, color.NewColor(0, 0, 0))
+	assertPixel(t, nearest, 1, 1, color.NewColor(0, 0, 0))
+	assertPixel(t, nearest, 7, 3, color.NewColor(3, 1, 0))
+	nearest, _ = c.Resize(2, 1, Nearest)
+	assertPixel(t, nearest, 0, 0, color.NewColor(1, 1, 0))
+
+	// Same size is an identity for every filter
+	for _, f := range []ResampleFilter{Nearest, Bilinear, Lanczos} {
+		same, err := c.Resize(4, 2, f)
+		assert.Nil(t, err)
+		for row := 0; row < 2; row++ {
+			for col := 0; col < 4; col++ {
+				assertPixel(t, same, col, row, color.NewColor(float64(col), float64(row), 0))
+			}
+		}
+	}
+
+	// Halving stretches the tent over two source pixels on each side, with the
+	// edge pixels repeated beyond the border
+	half, err := c.Resize(2, 1, Bilinear)
+	assert.Nil(t, err)
+	assertPixel(t, half, 0, 0, color.NewColor(0.625, 0.5, 0))
+	assertPixel(t, half, 1, 0, color.NewColor(2.375, 0.5, 0))
+
+	doubled, err := c.Resize(8, 2, Bilinear)
+	assert.Nil(t, err)
+	assertPixel(t, doubled, 0, 0, color.NewColor(0, 0, 0))
+	assertPixel(t, doubled, 1, 0, color.NewColor(0.25, 0, 0))
+	assertPixel(t, doubled, 2, 0, color.NewColor(0.75, 0, 0))
+
+	// Constant image stays constant with weights normalized near edges
+	flat := filledCanvas(5, 5, func(w, h int) *color.Color { return color.NewColor(0.3, 0.6, 0.9) })
+	for _, size := range [][2]int{{2, 3}, {11, 7}} {
+		resized, err := flat.Resize(size[0], size[1], Lanczos)
+		assert.Nil(t, err)
+		assertPixel(t, resized, size[0]-1, size[1]-1, color.NewColor(0.3, 0.6, 0.9))
+		assertPixel(t, resized, 0, 0, color.NewColor(0.3, 0.6, 0.9))
+	}
+
+	_, err = c.Resize(0, 2, Bilinear)
+	assert.NotNil(t, err)
+	_, err = NewCanvas(0, 0).Resize(1, 1, Bilinear)
+	assert.NotNil(t, err)
+	_, err = c.Resize(1, 1, ResampleFilter(42))
+	assert.NotNil(t, err)
+}
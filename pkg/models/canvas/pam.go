@@ -0,0 +1,42 @@
+package canvas
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+const pamMagicNumber = "P7"
+
+// SaveToPAM saves canvas to a .pam file (Netpbm portable arbitrary map), which
+// unlike PPM keeps the alpha channel
+func (c *Canvas) SaveToPAM(file string) error {
+	handle, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := writePAM(handle, c); err != nil {
+		handle.Close()
+		return err
+	}
+	return handle.Close()
+}
+
+func writePAM(handle io.Writer, c *Canvas) error {
+	w := bufio.NewWriter(handle)
+	header := fmt.Sprintf("%s\nWIDTH %d\nHEIGHT %d\nDEPTH 4\nMAXVAL %d\nTUPLTYPE RGB_ALPHA\nENDHDR\n",
+		pamMagicNumber, c.Width, c.Height, colorRange)
+	if _, err := w.WriteString(header); err != nil {
+		return err
+	}
+	for h, row := range c.Colors {
+		for x, currColor := range row {
+			c255, a255 := straightTo255Range(currColor, c.alpha(x, h))
+			if _, err := w.Write([]byte{uint8(c255.Red), uint8(c255.Green), uint8(c255.Blue), a255}); err != nil {
+				return err
+			}
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,44 @@
+package canvas
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanvasToPAM(t *testing.T) {
+	c := NewTransparentCanvas(2, 1)
+	c.WritePixelAlpha(1, 0, color.NewColor(0.5, 0.25, 0), 0.5)
+
+	var b bytes.Buffer
+	assert.Nil(t, writePAM(&b, c))
+	header := "P7\nWIDTH 2\nHEIGHT 1\nDEPTH 4\nMAXVAL 255\nTUPLTYPE RGB_ALPHA\nENDHDR\n"
+	assert.Equal(t, header, b.String()[:len(header)])
+	assert.Equal(t, []byte{0, 0, 0, 0, 255, 128, 0, 128}, b.Bytes()[len(header):])
+
+	got, err := ReadPAM(&b)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, got.Width)
+	alpha, _ := got.GetAlpha(1, 0)
+	assert.InDelta(t, 0.5, alpha, 1.0/255)
+	p, _ := got.GetPixel(1, 0)
+	assert.InDelta(t, 0.5, p.Red, 1.0/255)
+	assert.InDelta(t, 0.25, p.Green, 1.0/255)
+}
+
+func TestReadPAMErrors(t *testing.T) {
+	tests := map[string]string{
+		"wrong magic":   "P6\n1 1\n255\n",
+		"missing depth": "P7\nWIDTH 1\nHEIGHT 1\nMAXVAL 255\nENDHDR\n",
+		"short data":    "P7\nWIDTH 1\nHEIGHT 1\nDEPTH 4\nMAXVAL 255\nENDHDR\n\x00",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ReadPAM(strings.NewReader(input))
+			assert.NotNil(t, err)
+		})
+	}
+}
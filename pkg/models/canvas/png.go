@@ -0,0 +1,54 @@
+package canvas
+
+import (
+	"image"
+	imagecolor "image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+// SaveToPNG saves canvas to a .png file
+func (c *Canvas) SaveToPNG(file string) error {
+	handle, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := writePNG(handle, c); err != nil {
+		handle.Close()
+		return err
+	}
+	return handle.Close()
+}
+
+func writePNG(w io.Writer, c *Canvas) error {
+	return png.Encode(w, c.ToImage())
+}
+
+// ToImage converts canvas to an 8-bit image with alpha, clamping colors the same
+// way SaveToPPM does
+func (c *Canvas) ToImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, c.Width, c.Height))
+	for h, row := range c.Colors {
+		for w, currColor := range row {
+			c255, a255 := straightTo255Range(currColor, c.alpha(w, h))
+			img.SetNRGBA(w, h, imagecolor.NRGBA{
+				R: uint8(c255.Red),
+				G: uint8(c255.Green),
+				B: uint8(c255.Blue),
+				A: a255,
+			})
+		}
+	}
+	return img
+}
+
+// straightTo255Range converts a premultiplied color to straight 8-bit color and
+// alpha components used by image formats with an alpha channel
+func straightTo255Range(c *color.Color, alpha float64) (*color.Color, uint8) {
+	alpha = math.Min(math.Max(alpha, 0), 1)
+	return color.ColorTo255Range(color.Unpremultiply(c, alpha)), uint8(math.Round(alpha * colorRange))
+}
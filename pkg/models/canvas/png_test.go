@@ -0,0 +1,54 @@
+package canvas
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPNGAlphaRoundTrip(t *testing.T) {
+	c := NewTransparentCanvas(2, 1)
+	c.WritePixelAlpha(1, 0, color.NewColor(0.5, 0, 0), 0.5)
+
+	var b bytes.Buffer
+	assert.Nil(t, writePNG(&b, c))
+	img, err := png.Decode(&b)
+	assert.Nil(t, err)
+	_, _, _, a := img.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0), a)
+
+	got := FromImage(img)
+	alpha, _ := got.GetAlpha(1, 0)
+	assert.InDelta(t, 0.5, alpha, 1.0/255)
+	p, _ := got.GetPixel(1, 0)
+	assert.InDelta(t, 0.5, p.Red, 1.0/255)
+}
+
+func TestCanvasToPNG(t *testing.T) {
+	c := prepareCanvas(3, 2, []*color.Color{color.NewColor(1.5, 0, 0), color.NewColor(0, 0.5, 0),
+		color.NewColor(-0.5, 0, 1)}, []int{0, 0, 1, 0, 2, 1})
+
+	var b bytes.Buffer
+	assert.Nil(t, writePNG(&b, c))
+	img, err := png.Decode(&b)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, img.Bounds().Dx())
+	assert.Equal(t, 2, img.Bounds().Dy())
+
+	tests := []struct {
+		x, y       int
+		r, g, b, a uint32
+	}{
+		{x: 0, y: 0, r: 255, g: 0, b: 0, a: 255},
+		{x: 1, y: 0, r: 0, g: 128, b: 0, a: 255},
+		{x: 2, y: 1, r: 0, g: 0, b: 255, a: 255},
+		{x: 0, y: 1, r: 0, g: 0, b: 0, a: 255},
+	}
+	for _, tc := range tests {
+		r, g, b, a := img.At(tc.x, tc.y).RGBA()
+		assert.Equal(t, []uint32{tc.r, tc.g, tc.b, tc.a}, []uint32{r >> 8, g >> 8, b >> 8, a >> 8})
+	}
+}
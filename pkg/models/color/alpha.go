@@ -0,0 +1,49 @@
+package color
+
+// Colors with alpha are kept premultiplied, i.e. every component is already
+// scaled by the alpha value. Porter-Duff operators below combine a source color
+// a with alpha aa and a destination color b with alpha ab and return the
+// premultiplied result together with its alpha.
+
+// Premultiply scales color by alpha
+func Premultiply(c *Color, alpha float64) *Color {
+	return Scale(c, alpha)
+}
+
+// Unpremultiply divides premultiplied color by alpha, fully transparent colors
+// become black
+func Unpremultiply(c *Color, alpha float64) *Color {
+	if alpha <= 0 {
+		return NewColor(0, 0, 0)
+	}
+	return Scale(c, 1/alpha)
+}
+
+// Over places a on top of b
+func Over(a *Color, aa float64, b *Color, ab float64) (*Color, float64) {
+	return porterDuff(a, aa, b, ab, 1, 1-aa)
+}
+
+// In keeps the part of a that is inside of b
+func In(a *Color, aa float64, b *Color, ab float64) (*Color, float64) {
+	return porterDuff(a, aa, b, ab, ab, 0)
+}
+
+// Out keeps the part of a that is outside of b
+func Out(a *Color, aa float64, b *Color, ab float64) (*Color, float64) {
+	return porterDuff(a, aa, b, ab, 1-ab, 0)
+}
+
+// Atop places the part of a that is inside of b on top of b
+func Atop(a *Color, aa float64, b *Color, ab float64) (*Color, float64) {
+	return porterDuff(a, aa, b, ab, ab, 1-aa)
+}
+
+// Xor keeps the parts of a and b that don't overlap
+func Xor(a *Color, aa float64, b *Color, ab float64) (*Color, float64) {
+	return porterDuff(a, aa, b, ab, 1-ab, 1-aa)
+}
+
+func porterDuff(a *Color, aa float64, b *Color, ab float64, fa, fb float64) (*Color, float64) {
+	return Add(Scale(a, fa), Scale(b, fb)), aa*fa + ab*fb
+}
@@ -0,0 +1,49 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPremultiply(t *testing.T) {
+	c := NewColor(1, 0.5, 0.2)
+	p := Premultiply(c, 0.5)
+	assert.True(t, Equals(p, NewColor(0.5, 0.25, 0.1)))
+	assert.True(t, Equals(Unpremultiply(p, 0.5), c))
+	assert.True(t, Equals(Unpremultiply(p, 0), NewColor(0, 0, 0)))
+}
+
+func TestPorterDuff(t *testing.T) {
+	// Half transparent red over half transparent blue, both premultiplied
+	red := NewColor(0.5, 0, 0)
+	blue := NewColor(0, 0, 0.5)
+
+	tests := map[string]struct {
+		op        func(*Color, float64, *Color, float64) (*Color, float64)
+		want      *Color
+		wantAlpha float64
+	}{
+		"over": {op: Over, want: NewColor(0.5, 0, 0.25), wantAlpha: 0.75},
+		"in":   {op: In, want: NewColor(0.25, 0, 0), wantAlpha: 0.25},
+		"out":  {op: Out, want: NewColor(0.25, 0, 0), wantAlpha: 0.25},
+		"atop": {op: Atop, want: NewColor(0.25, 0, 0.25), wantAlpha: 0.5},
+		"xor":  {op: Xor, want: NewColor(0.25, 0, 0.25), wantAlpha: 0.5},
+	}
+
+	for name, tc := range tests {
+		got, alpha := tc.op(red, 0.5, blue, 0.5)
+		if !Equals(got, tc.want) || !util.FloatEquals(alpha, tc.wantAlpha) {
+			t.Fatalf("%s: expected: %v %v, got %v %v", name, tc.want, tc.wantAlpha, got, alpha)
+		}
+	}
+
+	// Opaque source covers everything, transparent source leaves destination as is
+	got, alpha := Over(NewColor(1, 0, 0), 1, blue, 0.5)
+	assert.True(t, Equals(got, NewColor(1, 0, 0)))
+	assert.Equal(t, 1.0, alpha)
+	got, alpha = Over(NewColor(0, 0, 0), 0, blue, 0.5)
+	assert.True(t, Equals(got, blue))
+	assert.Equal(t, 0.5, alpha)
+}
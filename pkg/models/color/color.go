@@ -40,9 +40,12 @@ func Multiply(c1, c2 *Color) *Color {
 	return &Color{c1.Red * c2.Red, c1.Green * c2.Green, c1.Blue * c2.Blue}
 }
 
-func Equals(c1, c2 *Color) bool {
-	return util.FloatEquals(c1.Red, c2.Red) && util.FloatEquals(c1.Green, c2.Green) &&
-		util.FloatEquals(c1.Blue, c2.Blue)
+// Equals compares two colors for equality using the optional tolerance, or
+// util.DefaultTolerance if none is given
+func Equals(c1, c2 *Color, tol ...util.Tolerance) bool {
+	t := util.ToleranceOrDefault(tol...)
+	return t.Equals(c1.Red, c2.Red) && t.Equals(c1.Green, c2.Green) &&
+		t.Equals(c1.Blue, c2.Blue)
 }
 
 func ColorTo255Range(c *Color) *Color {
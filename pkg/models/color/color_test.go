@@ -21,6 +21,10 @@ func TestEquals(t *testing.T) {
 	assert.True(t, Equals(c1, c2))
 	assert.False(t, Equals(c1, c3))
 	assert.False(t, Equals(c2, c3))
+
+	c4 := NewColor(0.9, 0.6, 0.76)
+	assert.True(t, Equals(c1, c4, util.Tolerance{Abs: 0.05}))
+	assert.False(t, Equals(c1, NewColor(0.9, 0.6, 0.75000001), util.Tolerance{}))
 }
 
 func TestAddingColors(t *testing.T) {
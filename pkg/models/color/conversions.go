@@ -0,0 +1,175 @@
+package color
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LinearToSRGB encodes every component of a linear color with the sRGB transfer
+// function
+func LinearToSRGB(c *Color) *Color {
+	return &Color{
+		linearToSRGB(c.Red),
+		linearToSRGB(c.Green),
+		linearToSRGB(c.Blue),
+	}
+}
+
+// SRGBToLinear decodes every component of an sRGB encoded color to linear space,
+// which is the space all shading math should be done in
+func SRGBToLinear(c *Color) *Color {
+	return &Color{
+		srgbToLinear(c.Red),
+		srgbToLinear(c.Green),
+		srgbToLinear(c.Blue),
+	}
+}
+
+func linearToSRGB(c float64) float64 {
+	if c <= 0.0031308 {
+		return 12.92 * c
+	}
+	return 1.055*math.Pow(c, 1/2.4) - 0.055
+}
+
+func srgbToLinear(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+// Luminance returns relative luminance of a linear color as defined by Rec. 709
+func Luminance(c *Color) float64 {
+	return 0.2126*c.Red + 0.7152*c.Green + 0.0722*c.Blue
+}
+
+// Lerp linearly interpolates between two colors, returning c1 for t = 0 and c2
+// for t = 1
+func Lerp(c1, c2 *Color, t float64) *Color {
+	return Add(Scale(c1, 1-t), Scale(c2, t))
+}
+
+// Mix returns an average of all of the colors, or black if there are none
+func Mix(colors ...*Color) *Color {
+	result := NewColor(0, 0, 0)
+	if len(colors) == 0 {
+		return result
+	}
+	for _, c := range colors {
+		result = Add(result, c)
+	}
+	return Scale(result, 1/float64(len(colors)))
+}
+
+// ToHSV converts color to hue in degrees [0, 360), saturation and value in [0, 1].
+// Components are used as they are, so for colors coming from a color picker
+// convert the result of FromHSV with SRGBToLinear before shading.
+func ToHSV(c *Color) (h, s, v float64) {
+	max := math.Max(c.Red, math.Max(c.Green, c.Blue))
+	min := math.Min(c.Red, math.Min(c.Green, c.Blue))
+	v = max
+	if max > 0 {
+		s = (max - min) / max
+	}
+	return hue(c, max, min), s, v
+}
+
+// FromHSV creates a color from hue in degrees, saturation and value in [0, 1]
+func FromHSV(h, s, v float64) *Color {
+	chroma := v * s
+	return fromHueChroma(h, chroma, v-chroma)
+}
+
+// ToHSL converts color to hue in degrees [0, 360), saturation and lightness in [0, 1]
+func ToHSL(c *Color) (h, s, l float64) {
+	max := math.Max(c.Red, math.Max(c.Green, c.Blue))
+	min := math.Min(c.Red, math.Min(c.Green, c.Blue))
+	l = (max + min) / 2
+	if d := 1 - math.Abs(2*l-1); d > 0 {
+		s = (max - min) / d
+	}
+	return hue(c, max, min), s, l
+}
+
+// FromHSL creates a color from hue in degrees, saturation and lightness in [0, 1]
+func FromHSL(h, s, l float64) *Color {
+	chroma := (1 - math.Abs(2*l-1)) * s
+	return fromHueChroma(h, chroma, l-chroma/2)
+}
+
+func hue(c *Color, max, min float64) float64 {
+	d := max - min
+	if d == 0 {
+		return 0
+	}
+	var h float64
+	switch max {
+	case c.Red:
+		h = math.Mod((c.Green-c.Blue)/d, 6)
+	case c.Green:
+		h = (c.Blue-c.Red)/d + 2
+	default:
+		h = (c.Red-c.Green)/d + 4
+	}
+	h *= 60
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+func fromHueChroma(h, chroma, m float64) *Color {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	sector := h / 60
+	x := chroma * (1 - math.Abs(math.Mod(sector, 2)-1))
+	var r, g, b float64
+	switch {
+	case sector < 1:
+		r, g, b = chroma, x, 0
+	case sector < 2:
+		r, g, b = x, chroma, 0
+	case sector < 3:
+		r, g, b = 0, chroma, x
+	case sector < 4:
+		r, g, b = 0, x, chroma
+	case sector < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return &Color{r + m, g + m, b + m}
+}
+
+// ParseHex parses a color written as "#rrggbb" or "#rgb" (the leading # is
+// optional). Hex colors are conventionally sRGB encoded, so use SRGBToLinear on
+// the result before shading.
+func ParseHex(s string) (*Color, error) {
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("invalid hex color %q", s)
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex color %q", s)
+	}
+	return &Color{
+		float64(value>>16&0xff) / 255,
+		float64(value>>8&0xff) / 255,
+		float64(value&0xff) / 255,
+	}, nil
+}
+
+// ToHex formats color as "#rrggbb", components outside of [0, 1] range are clamped
+func ToHex(c *Color) string {
+	c255 := ColorTo255Range(c)
+	return fmt.Sprintf("#%02x%02x%02x", int(c255.Red), int(c255.Green), int(c255.Blue))
+}
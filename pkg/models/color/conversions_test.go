@@ -0,0 +1,122 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSRGBConversion(t *testing.T) {
+	tests := map[string]struct {
+		linear *Color
+		srgb   *Color
+	}{
+		"black":          {linear: NewColor(0, 0, 0), srgb: NewColor(0, 0, 0)},
+		"white":          {linear: NewColor(1, 1, 1), srgb: NewColor(1, 1, 1)},
+		"mid gray":       {linear: NewColor(0.21404, 0.5, 0.18), srgb: NewColor(0.5, 0.73536, 0.46135)},
+		"linear segment": {linear: NewColor(0.001, 0.002, 0.003), srgb: NewColor(0.01292, 0.02584, 0.03876)},
+	}
+
+	for name, tc := range tests {
+		got := LinearToSRGB(tc.linear)
+		if !Equals(got, tc.srgb) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.srgb, got)
+		}
+		got = SRGBToLinear(tc.srgb)
+		if !Equals(got, tc.linear) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.linear, got)
+		}
+	}
+}
+
+func TestLuminance(t *testing.T) {
+	assert.True(t, util.FloatEquals(Luminance(NewColor(1, 1, 1)), 1))
+	assert.True(t, util.FloatEquals(Luminance(NewColor(0, 1, 0)), 0.7152))
+	assert.True(t, util.FloatEquals(Luminance(NewColor(0.5, 0, 0.5)), 0.1424))
+}
+
+func TestLerpAndMix(t *testing.T) {
+	c1 := NewColor(0, 0.5, 1)
+	c2 := NewColor(1, 0.5, 0)
+	assert.True(t, Equals(Lerp(c1, c2, 0), c1))
+	assert.True(t, Equals(Lerp(c1, c2, 1), c2))
+	assert.True(t, Equals(Lerp(c1, c2, 0.25), NewColor(0.25, 0.5, 0.75)))
+	assert.True(t, Equals(Mix(c1, c2, NewColor(0.5, 0.5, 0.5)), NewColor(0.5, 0.5, 0.5)))
+	assert.True(t, Equals(Mix(), NewColor(0, 0, 0)))
+}
+
+func TestHSVAndHSL(t *testing.T) {
+	tests := map[string]struct {
+		rgb      *Color
+		h, sv, v float64
+		sl, l    float64
+	}{
+		"red":     {rgb: NewColor(1, 0, 0), h: 0, sv: 1, v: 1, sl: 1, l: 0.5},
+		"green":   {rgb: NewColor(0, 1, 0), h: 120, sv: 1, v: 1, sl: 1, l: 0.5},
+		"blue":    {rgb: NewColor(0, 0, 1), h: 240, sv: 1, v: 1, sl: 1, l: 0.5},
+		"magenta": {rgb: NewColor(1, 0, 1), h: 300, sv: 1, v: 1, sl: 1, l: 0.5},
+		"orange":  {rgb: NewColor(1, 0.5, 0), h: 30, sv: 1, v: 1, sl: 1, l: 0.5},
+		"gray":    {rgb: NewColor(0.5, 0.5, 0.5), h: 0, sv: 0, v: 0.5, sl: 0, l: 0.5},
+		"black":   {rgb: NewColor(0, 0, 0), h: 0, sv: 0, v: 0, sl: 0, l: 0},
+		"white":   {rgb: NewColor(1, 1, 1), h: 0, sv: 0, v: 1, sl: 0, l: 1},
+		"muted":   {rgb: NewColor(0.2, 0.4, 0.3), h: 150, sv: 0.5, v: 0.4, sl: 1.0 / 3, l: 0.3},
+	}
+
+	for name, tc := range tests {
+		h, s, v := ToHSV(tc.rgb)
+		if !util.FloatEquals(h, tc.h) || !util.FloatEquals(s, tc.sv) || !util.FloatEquals(v, tc.v) {
+			t.Fatalf("%s: expected HSV: %v %v %v, got %v %v %v", name, tc.h, tc.sv, tc.v, h, s, v)
+		}
+		if got := FromHSV(h, s, v); !Equals(got, tc.rgb) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.rgb, got)
+		}
+
+		h, s, l := ToHSL(tc.rgb)
+		if !util.FloatEquals(h, tc.h) || !util.FloatEquals(s, tc.sl) || !util.FloatEquals(l, tc.l) {
+			t.Fatalf("%s: expected HSL: %v %v %v, got %v %v %v", name, tc.h, tc.sl, tc.l, h, s, l)
+		}
+		if got := FromHSL(h, s, l); !Equals(got, tc.rgb) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.rgb, got)
+		}
+	}
+
+	assert.True(t, Equals(FromHSV(-240, 1, 1), NewColor(0, 1, 0)))
+	assert.True(t, Equals(FromHSL(480, 1, 0.5), NewColor(0, 1, 0)))
+}
+
+func TestHex(t *testing.T) {
+	tests := map[string]struct {
+		input string
+		want  *Color
+		err   bool
+	}{
+		"full":          {input: "#ff8800", want: NewColor(1, 0x88/255.0, 0)},
+		"without hash":  {input: "0080ff", want: NewColor(0, 0x80/255.0, 1)},
+		"short":         {input: "#f80", want: NewColor(1, 0x88/255.0, 0)},
+		"uppercase":     {input: "#FFFFFF", want: NewColor(1, 1, 1)},
+		"too short":     {input: "#ff80", err: true},
+		"not hex":       {input: "#gg0000", err: true},
+		"empty":         {input: "", err: true},
+		"signed number": {input: "#+12345", err: true},
+	}
+
+	for name, tc := range tests {
+		got, err := ParseHex(tc.input)
+		if tc.err {
+			assert.NotNil(t, err, name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		if !Equals(got, tc.want) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+
+	for _, hex := range []string{"#ff8800", "#000000", "#123abc", "#ffffff"} {
+		c, err := ParseHex(hex)
+		assert.Nil(t, err)
+		assert.Equal(t, hex, ToHex(c))
+	}
+	assert.Equal(t, "#ff0000", ToHex(NewColor(1.5, -0.5, 0)))
+}
@@ -0,0 +1,265 @@
+package matrix
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+)
+
+// LU is the LU decomposition of a square matrix with partial pivoting, i.e.
+// P * A = L * U, where P permutes rows, L is lower triangular with ones on the
+// diagonal and U is upper triangular
+type LU struct {
+	// lu stores L below the diagonal and U on and above it
+	lu [][]float64
+	// pivot[i] is the row of the original matrix that ended up in row i
+	pivot []int
+	// sign is -1 if rows were swapped an odd number of times
+	sign     float64
+	singular bool
+}
+
+// NewLU decomposes square matrix m in O(n^3). Singular matrices are decomposed
+// too, but they can't be used to solve equations. Pivots are compared to zero
+// using the optional tolerance, or util.DefaultTolerance if none is given, see
+// isNegligible.
+func NewLU(m *Matrix, tol ...util.Tolerance) (*LU, error) {
+	n := len(m.elements)
+	if n == 0 || len(m.elements[0]) != n {
+		return nil, errors.New("LU decomposition needs a non-empty square matrix")
+	}
+	lu := copyElements(m.elements)
+	d := &LU{lu: lu, pivot: make([]int, n), sign: 1}
+	for i := range d.pivot {
+		d.pivot[i] = i
+	}
+
+	t := util.ToleranceOrDefault(tol...)
+	scales := rowScales(lu)
+	for col := 0; col < n; col++ {
+		// Partial pivoting moves the largest remaining element to the diagonal,
+		// which keeps rounding errors small
+		p := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(lu[row][col]) > math.Abs(lu[p][col]) {
+				p = row
+			}
+		}
+		if p != col {
+			lu[p], lu[col] = lu[col], lu[p]
+			d.pivot[p], d.pivot[col] = d.pivot[col], d.pivot[p]
+			d.sign = -d.sign
+		}
+		if isNegligible(lu[col][col], scales[d.pivot[col]], t) {
+			d.singular = true
+			continue
+		}
+		for row := col + 1; row < n; row++ {
+			f := lu[row][col] / lu[col][col]
+			lu[row][col] = f
+			for k := col + 1; k < n; k++ {
+				lu[row][k] -= f * lu[col][k]
+			}
+		}
+	}
+	return d, nil
+}
+
+// IsSingular returns true if the decomposed matrix has no inverse
+func (d *LU) IsSingular() bool {
+	return d.singular
+}
+
+// Determinant returns the determinant of the decomposed matrix
+func (d *LU) Determinant() float64 {
+	result := d.sign
+	for i := range d.lu {
+		result *= d.lu[i][i]
+	}
+	return result
+}
+
+// L returns the lower triangular factor
+func (d *LU) L() *Matrix {
+	result := make([][]float64, len(d.lu))
+	for row := range result {
+		result[row] = make([]float64, len(d.lu))
+		copy(result[row], d.lu[row][:row])
+		result[row][row] = 1
+	}
+	return NewMatrix(result)
+}
+
+// U returns the upper triangular factor
+func (d *LU) U() *Matrix {
+	result := make([][]float64, len(d.lu))
+	for row := range result {
+		result[row] = make([]float64, len(d.lu))
+		copy(result[row][row:], d.lu[row][row:])
+	}
+	return NewMatrix(result)
+}
+
+// P returns the permutation matrix of row swaps
+func (d *LU) P() *Matrix {
+	result := make([][]float64, len(d.lu))
+	for row := range result {
+		result[row] = make([]float64, len(d.lu))
+		result[row][d.pivot[row]] = 1
+	}
+	return NewMatrix(result)
+}
+
+// Solve returns x such that A * x = b, where A is the decomposed matrix
+func (d *LU) Solve(b []float64) ([]float64, error) {
+	n := len(d.lu)
+	if len(b) != n {
+		return nil, errors.New("incompatible dimensions for solving linear system")
+	}
+	if d.singular {
+		return nil, errors.New("matrix is singular")
+	}
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = b[d.pivot[i]]
+	}
+	// Forward substitution with L and back substitution with U
+	for row := 0; row < n; row++ {
+		for k := 0; k < row; k++ {
+			x[row] -= d.lu[row][k] * x[k]
+		}
+	}
+	for row := n - 1; row >= 0; row-- {
+		for k := row + 1; k < n; k++ {
+			x[row] -= d.lu[row][k] * x[k]
+		}
+		x[row] /= d.lu[row][row]
+	}
+	return x, nil
+}
+
+// Inverse returns the inverse of the decomposed matrix
+func (d *LU) Inverse() (*Matrix, error) {
+	n := len(d.lu)
+	result := make([][]float64, n)
+	for row := range result {
+		result[row] = make([]float64, n)
+	}
+	unit := make([]float64, n)
+	for col := 0; col < n; col++ {
+		unit[col] = 1
+		x, err := d.Solve(unit)
+		if err != nil {
+			return nil, err
+		}
+		unit[col] = 0
+		for row := range x {
+			result[row][col] = x[row]
+		}
+	}
+	return NewMatrix(result), nil
+}
+
+// Solve returns x such that a * x = b for square matrix a
+func Solve(a *Matrix, b []float64) ([]float64, error) {
+	d, err := NewLU(a)
+	if err != nil {
+		return nil, err
+	}
+	return d.Solve(b)
+}
+
+// Rank returns the number of linearly independent rows of matrix m of any
+// dimensions using the optional tolerance, or util.DefaultTolerance if none is
+// given
+func Rank(m *Matrix, tol ...util.Tolerance) int {
+	rows := copyElements(m.elements)
+	if len(rows) == 0 {
+		return 0
+	}
+	t := util.ToleranceOrDefault(tol...)
+	scales := rowScales(rows)
+	rank := 0
+	for col := 0; col < len(rows[0]) && rank < len(rows); col++ {
+		p := rank
+		for row := rank + 1; row < len(rows); row++ {
+			if math.Abs(rows[row][col]) > math.Abs(rows[p][col]) {
+				p = row
+			}
+		}
+		if isNegligible(rows[p][col], scales[p], t) {
+			continue
+		}
+		rows[p], rows[rank] = rows[rank], rows[p]
+		scales[p], scales[rank] = scales[rank], scales[p]
+		for row := rank + 1; row < len(rows); row++ {
+			f := rows[row][col] / rows[rank][col]
+			for k := col; k < len(rows[row]); k++ {
+				rows[row][k] -= f * rows[rank][k]
+			}
+		}
+		rank++
+	}
+	return rank
+}
+
+// ConditionNumber returns the condition number of square matrix m in the 1-norm,
+// which tells how much rounding errors can grow when solving equations with m.
+// It's +Inf for singular matrices.
+func ConditionNumber(m *Matrix) (float64, error) {
+	d, err := NewLU(m)
+	if err != nil {
+		return 0, err
+	}
+	if d.singular {
+		return math.Inf(1), nil
+	}
+	inverse, err := d.Inverse()
+	if err != nil {
+		return 0, err
+	}
+	return norm1(m.elements) * norm1(inverse.elements), nil
+}
+
+// norm1 returns the largest sum of absolute values in a column
+func norm1(elements [][]float64) float64 {
+	result := 0.0
+	for col := range elements[0] {
+		sum := 0.0
+		for row := range elements {
+			sum += math.Abs(elements[row][col])
+		}
+		result = math.Max(result, sum)
+	}
+	return result
+}
+
+// isNegligible checks if pivot v is zero compared to scale, the largest element
+// of the row it came from, i.e. if scale and scale + v are equal within the
+// relative part of t. Comparing against the row rather than the whole matrix
+// keeps a row of tiny elements independent of much larger rows. The absolute
+// part of t isn't used, because it would make rows of small elements, like the
+// ones of a scaling by 1e-6, singular.
+func isNegligible(v, scale float64, t util.Tolerance) bool {
+	return math.Abs(v) <= t.Rel*scale
+}
+
+// rowScales returns the largest absolute value in every row
+func rowScales(elements [][]float64) []float64 {
+	result := make([]float64, len(elements))
+	for row := range elements {
+		for _, v := range elements[row] {
+			result[row] = math.Max(result[row], math.Abs(v))
+		}
+	}
+	return result
+}
+
+func copyElements(elements [][]float64) [][]float64 {
+	result := make([][]float64, len(elements))
+	for row := range elements {
+		result[row] = append([]float64(nil), elements[row]...)
+	}
+	return result
+}
@@ -0,0 +1,209 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLUDecomposition(t *testing.T) {
+	a := NewMatrix([][]float64{
+		{-2, -8, 3, 5},
+		{-3, 1, 7, 3},
+		{1, 2, -9, 6},
+		{-6, 7, 7, -9},
+	})
+	d, err := NewLU(a)
+	assert.Nil(t, err)
+	assert.False(t, d.IsSingular())
+
+	pa, err := Multiply(d.P(), a)
+	assert.Nil(t, err)
+	lu, err := Multiply(d.L(), d.U())
+	assert.Nil(t, err)
+	assert.True(t, IsEqual(pa, lu))
+	assert.True(t, util.FloatEquals(d.Determinant(), -4071))
+
+	for _, m := range []*Matrix{NewMatrix([][]float64{}), NewMatrix([][]float64{{1, 2}})} {
+		_, err = NewLU(m)
+		assert.NotNil(t, err)
+	}
+}
+
+func TestDeterminantOfBigMatrices(t *testing.T) {
+	tests := map[string]struct {
+		m    *Matrix
+		want float64
+	}{
+		"1x1":      {m: NewMatrix([][]float64{{-3}}), want: -3},
+		"identity": {m: Identity(7), want: 1},
+		"5x5": {m: NewMatrix([][]float64{
+			{2, 0, 0, 0, 1},
+			{0, 3, 0, 0, 0},
+			{0, 0, 1, 4, 0},
+			{0, 0, 0, 2, 0},
+			{1, 0, 0, 0, 1},
+		}), want: 6},
+		"singular 6x6": {m: NewMatrix([][]float64{
+			{1, 2, 3, 4, 5, 6},
+			{2, 4, 6, 8, 10, 12},
+			{0, 1, 0, 1, 0, 1},
+			{1, 0, 1, 0, 1, 0},
+			{3, 1, 4, 1, 5, 9},
+			{2, 7, 1, 8, 2, 8},
+		}), want: 0},
+	}
+
+	for name, tc := range tests {
+		got := GetDeterminant(tc.m)
+		if !util.FloatEquals(got, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestSolve(t *testing.T) {
+	a := NewMatrix([][]float64{
+		{2, 1, -1},
+		{-3, -1, 2},
+		{-2, 1, 2},
+	})
+	x, err := Solve(a, []float64{8, -11, -3})
+	assert.Nil(t, err)
+	assert.InDeltaSlice(t, []float64{2, 3, -1}, x, 1e-12)
+
+	_, err = Solve(a, []float64{1, 2})
+	assert.NotNil(t, err)
+	_, err = Solve(NewMatrix([][]float64{{1, 2}, {2, 4}}), []float64{1, 2})
+	assert.NotNil(t, err)
+	_, err = Solve(NewMatrix([][]float64{{1, 2, 3}}), []float64{1})
+	assert.NotNil(t, err)
+}
+
+func TestRank(t *testing.T) {
+	tests := map[string]struct {
+		m    *Matrix
+		want int
+	}{
+		"empty":         {m: NewMatrix([][]float64{}), want: 0},
+		"zero":          {m: NewMatrix([][]float64{{0, 0}, {0, 0}}), want: 0},
+		"full":          {m: Identity(4), want: 4},
+		"dependent row": {m: NewMatrix([][]float64{{1, 2, 3}, {2, 4, 6}, {1, 0, 1}}), want: 2},
+		"wide":          {m: NewMatrix([][]float64{{1, 2, 3, 4}, {2, 4, 6, 8}}), want: 1},
+		"tall":          {m: NewMatrix([][]float64{{1, 0}, {0, 1}, {1, 1}}), want: 2},
+		"almost dependent": {m: NewMatrix([][]float64{
+			{1, 2},
+			{1, 2 + 1e-13},
+		}), want: 1},
+		"small difference": {m: NewMatrix([][]float64{
+			{1, 2},
+			{1, 2 + 1e-7},
+		}), want: 2},
+		"tiny row": {m: NewMatrix([][]float64{
+			{1e6, 0},
+			{0, 1e-9},
+		}), want: 2},
+	}
+
+	for name, tc := range tests {
+		if got := Rank(tc.m); got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestConditionNumber(t *testing.T) {
+	c, err := ConditionNumber(Identity(4))
+	assert.Nil(t, err)
+	assert.Equal(t, 1.0, c)
+
+	c, err = ConditionNumber(NewMatrix([][]float64{{1, 0}, {0, 1e-3}}))
+	assert.Nil(t, err)
+	assert.InDelta(t, 1000, c, 1e-9)
+
+	c, err = ConditionNumber(NewMatrix([][]float64{{1, 2}, {2, 4}}))
+	assert.Nil(t, err)
+	assert.True(t, math.IsInf(c, 1))
+
+	_, err = ConditionNumber(NewMatrix([][]float64{{1, 2}}))
+	assert.NotNil(t, err)
+}
+
+func TestInvertibilityTolerance(t *testing.T) {
+	// Small objects have tiny determinants, but are still invertible
+	small := Scaling(0.01, 0.01, 0.01)
+	assert.True(t, IsInvertible(small))
+	inverse, err := GetInverse(small)
+	assert.Nil(t, err)
+	assert.True(t, IsEqual(inverse, Scaling(100, 100, 100)))
+
+	// Rows that differ only by rounding errors are dependent
+	almostSingular := NewMatrix([][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+		{7, 8, 9 + 1e-12},
+	})
+	assert.False(t, IsInvertible(almostSingular))
+	_, err = GetInverse(almostSingular)
+	assert.NotNil(t, err)
+}
+
+func TestInvertingLargeTransformations(t *testing.T) {
+	mixed, err := Chain(Scaling(0.01, 0.01, 0.01), Translation(1000, 0, 0))
+	assert.Nil(t, err)
+	tests := map[string]struct {
+		m, want *Matrix
+	}{
+		"large translation":   {m: Translation(1e6, 0, 0), want: Translation(-1e6, 0, 0)},
+		"distant camera":      {m: Translation(3e5, -2e5, 1e7), want: Translation(-3e5, 2e5, -1e7)},
+		"tiny scale":          {m: Scaling(1e-6, 1, 1e-6), want: Scaling(1e6, 1, 1e6)},
+		"scale and translate": {m: mixed, want: mustChain(t, Translation(-1000, 0, 0), Scaling(100, 100, 100))},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, IsInvertible(tc.m))
+			inverse, err := GetInverse(tc.m)
+			assert.Nil(t, err)
+			assert.True(t, IsEqual(inverse, tc.want), "inverse %v", inverse)
+		})
+	}
+}
+
+func TestSingularityTolerance(t *testing.T) {
+	m := NewMatrix([][]float64{{1, 2}, {1, 2 + 1e-7}})
+	assert.True(t, IsInvertible(m))
+	assert.False(t, IsInvertible(m, util.Tolerance{Rel: 1e-6}))
+	assert.Equal(t, 1, Rank(m, util.Tolerance{Rel: 1e-6}))
+	d, err := NewLU(m, util.Tolerance{Rel: 1e-6})
+	assert.Nil(t, err)
+	assert.True(t, d.IsSingular())
+
+	// The default tolerance is used when none is given
+	old := util.DefaultTolerance
+	defer func() { util.DefaultTolerance = old }()
+	util.DefaultTolerance = util.Tolerance{Rel: 1e-6}
+	assert.False(t, IsInvertible(m))
+	util.DefaultTolerance = util.Tolerance{}
+	assert.True(t, IsInvertible(NewMatrix([][]float64{{1, 2}, {1, 2 + 1e-15}})))
+}
+
+func mustChain(t *testing.T, transforms ...*Matrix) *Matrix {
+	m, err := Chain(transforms...)
+	assert.Nil(t, err)
+	return m
+}
+
+func TestDeterminantOfNonSquareMatrices(t *testing.T) {
+	for _, m := range []*Matrix{
+		NewMatrix([][]float64{{1, 2, 3}}),
+		NewMatrix([][]float64{{1, 2}, {3, 4}, {5, 6}}),
+		NewMatrix([][]float64{{1, 2, 3, 4, 5, 6}, {1, 2, 3, 4, 5, 6}}),
+		NewMatrix([][]float64{{1}, {2}, {3}, {4}, {5}, {6}}),
+		NewMatrix(nil),
+	} {
+		assert.True(t, math.IsNaN(GetDeterminant(m)), "%vx%v", m.Height, m.Width)
+	}
+}
@@ -2,17 +2,23 @@ package matrix
 
 import (
 	"errors"
+	"math"
+	"strconv"
+	"strings"
 
 	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
 	"github.com/alex-petrov-vt/raytracer/pkg/util"
 )
 
+// Matrix is a 2D array of numbers with Height rows and Width columns
 type Matrix struct {
 	Width, Height int
 	elements      [][]float64
 }
 
-// NewMatrix creates new matrix given an input 2D array
+// NewMatrix creates new matrix given an input 2D array of rows. The input is
+// used as is without copying or checking that all rows have the same length,
+// which is meant for matrix literals, use FromRows for any other input.
 func NewMatrix(input [][]float64) *Matrix {
 	if len(input) == 0 {
 		return &Matrix{
@@ -22,12 +28,39 @@ func NewMatrix(input [][]float64) *Matrix {
 		}
 	}
 	return &Matrix{
-		len(input),
 		len(input[0]),
+		len(input),
 		input,
 	}
 }
 
+// FromRows creates new matrix from a copy of rows or returns error if the rows
+// don't all have the same non-zero length. No rows give an empty matrix.
+func FromRows(rows [][]float64) (*Matrix, error) {
+	for _, row := range rows {
+		if len(row) == 0 {
+			return nil, errors.New("matrix rows can't be empty")
+		}
+		if len(row) != len(rows[0]) {
+			return nil, errors.New("matrix rows must have the same length")
+		}
+	}
+	return NewMatrix(copyElements(rows)), nil
+}
+
+// Zeros returns a matrix of r rows and c columns filled with zeros. Both of the
+// dimensions have to be positive, or both zero for an empty matrix.
+func Zeros(r, c int) (*Matrix, error) {
+	if r < 0 || c < 0 || (r == 0) != (c == 0) {
+		return nil, errors.New("invalid matrix dimensions")
+	}
+	rows := make([][]float64, r)
+	for row := range rows {
+		rows[row] = make([]float64, c)
+	}
+	return NewMatrix(rows), nil
+}
+
 // GetElement returns an element with coordinates (row, col) or error if those
 // are outside of the boundaries of the matrix
 func (m *Matrix) GetElement(row, col int) (float64, error) {
@@ -37,15 +70,79 @@ func (m *Matrix) GetElement(row, col int) (float64, error) {
 	return m.elements[row][col], nil
 }
 
-// IsEqual compares two matrices for equality
-func IsEqual(m1, m2 *Matrix) bool {
+// SetElement sets the element with coordinates (row, col) or returns error if
+// those are outside of the boundaries of the matrix
+func (m *Matrix) SetElement(row, col int, value float64) error {
+	if row < 0 || row >= m.Height || col < 0 || col >= m.Width {
+		return errors.New("access elements outside of matrix")
+	}
+	m.elements[row][col] = value
+	return nil
+}
+
+// Row returns a copy of the row with index row
+func (m *Matrix) Row(row int) ([]float64, error) {
+	if row < 0 || row >= m.Height {
+		return nil, errors.New("access row outside of matrix")
+	}
+	return append([]float64(nil), m.elements[row]...), nil
+}
+
+// Col returns a copy of the column with index col
+func (m *Matrix) Col(col int) ([]float64, error) {
+	if col < 0 || col >= m.Width {
+		return nil, errors.New("access column outside of matrix")
+	}
+	result := make([]float64, m.Height)
+	for row := range result {
+		result[row] = m.elements[row][col]
+	}
+	return result, nil
+}
+
+// String formats the matrix as rows of right aligned columns
+func (m *Matrix) String() string {
+	if m.Height == 0 {
+		return "[]"
+	}
+	formatted := make([][]string, m.Height)
+	width := 0
+	for row := range formatted {
+		for _, v := range m.elements[row] {
+			s := strconv.FormatFloat(v, 'g', 6, 64)
+			if len(s) > width {
+				width = len(s)
+			}
+			formatted[row] = append(formatted[row], s)
+		}
+	}
+	var b strings.Builder
+	for row, values := range formatted {
+		if row > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("|")
+		for _, v := range values {
+			b.WriteString(" ")
+			b.WriteString(strings.Repeat(" ", width-len(v)))
+			b.WriteString(v)
+		}
+		b.WriteString(" |")
+	}
+	return b.String()
+}
+
+// IsEqual compares two matrices for equality using the optional tolerance, or
+// util.DefaultTolerance if none is given
+func IsEqual(m1, m2 *Matrix, tol ...util.Tolerance) bool {
 	if m1.Height != m2.Height || m1.Width != m2.Width {
 		return false
 	}
 
+	t := util.ToleranceOrDefault(tol...)
 	for row := 0; row < m1.Height; row++ {
 		for col := 0; col < m1.Width; col++ {
-			if !util.FloatEquals(m1.elements[row][col], m2.elements[row][col]) {
+			if !t.Equals(m1.elements[row][col], m2.elements[row][col]) {
 				return false
 			}
 		}
@@ -53,7 +150,7 @@ func IsEqual(m1, m2 *Matrix) bool {
 	return true
 }
 
-//Multiply multiplies two matrices together
+// Multiply multiplies two matrices together
 func Multiply(m1, m2 *Matrix) (*Matrix, error) {
 	if m1.Height == 0 || m1.Width == 0 || m2.Height == 0 || m2.Width == 0 {
 		return nil, errors.New("one or both of matrices are empty")
@@ -62,19 +159,7 @@ func Multiply(m1, m2 *Matrix) (*Matrix, error) {
 		return nil, errors.New("incompatible dimensions for matrix multiplication")
 	}
 
-	var newMatrix [][]float64
-	for row := 0; row < m1.Height; row++ {
-		var newRow []float64
-		for col := 0; col < m1.Width; col++ {
-			result := 0.0
-			for i := 0; i < m2.Width; i++ {
-				result += m1.elements[row][i] * m2.elements[i][col]
-			}
-			newRow = append(newRow, result)
-		}
-		newMatrix = append(newMatrix, newRow)
-	}
-	return NewMatrix(newMatrix), nil
+	return NewMatrix(multiplyBlocked(m1.elements, m2.elements, m2.Width)), nil
 }
 
 // MultiplyByVector multiplies a matrix by a vector
@@ -98,9 +183,9 @@ func MultiplyByVector(m *Matrix, v *vector.Vector) (*vector.Vector, error) {
 // Transpose transposes the matrix (turns rows into cols)
 func Transpose(m *Matrix) *Matrix {
 	var result [][]float64
-	for col := 0; col < m.Height; col++ {
+	for col := 0; col < m.Width; col++ {
 		var newRow []float64
-		for row := 0; row < m.Width; row++ {
+		for row := 0; row < m.Height; row++ {
 			newRow = append(newRow, m.elements[row][col])
 		}
 		result = append(result, newRow)
@@ -108,9 +193,25 @@ func Transpose(m *Matrix) *Matrix {
 	return NewMatrix(result)
 }
 
-// GetDeterminant returns a determinant of the matrix
+// GetDeterminant returns a determinant of the square matrix m. Matrices up to 4x4,
+// which covers every transformation, use cofactor expansion that is exact for
+// integer elements, bigger ones are decomposed with NewLU in O(n^3). It returns
+// NaN for empty or non-square matrices, which have no determinant.
 func GetDeterminant(m *Matrix) float64 {
 	result := 0.0
+	if m.Width == 0 || m.Width != m.Height {
+		return math.NaN()
+	}
+	if m.Width > 4 {
+		d, err := NewLU(m)
+		if err != nil {
+			return math.NaN()
+		}
+		return d.Determinant()
+	}
+	if m.Width == 1 && m.Height == 1 {
+		return m.elements[0][0]
+	}
 	if m.Width == 2 && m.Height == 2 {
 		a, _ := m.GetElement(0, 0)
 		d, _ := m.GetElement(1, 1)
@@ -160,25 +261,20 @@ func GetCofactor(m *Matrix, row, col int) float64 {
 	}
 }
 
-// IsInvertible checks if matrix has an inverse
-func IsInvertible(m *Matrix) bool {
-	return GetDeterminant(m) != 0
+// IsInvertible checks if square matrix m has an inverse. It decomposes m with
+// NewLU, which treats a pivot as zero when it's within the relative part of the
+// optional tolerance, or util.DefaultTolerance, of the largest element of its
+// row.
+func IsInvertible(m *Matrix, tol ...util.Tolerance) bool {
+	d, err := NewLU(m, tol...)
+	return err == nil && !d.IsSingular()
 }
 
 // GetInverse returns an inverse of matrix m
 func GetInverse(m *Matrix) (*Matrix, error) {
-	if !IsInvertible(m) {
+	d, err := NewLU(m)
+	if err != nil || d.IsSingular() {
 		return nil, errors.New("matrix is not invertible")
 	}
-	var result [][]float64
-	det := GetDeterminant(m)
-	for row := 0; row < m.Height; row++ {
-		var newRow []float64
-		for col := 0; col < m.Width; col++ {
-			c := GetCofactor(m, row, col)
-			newRow = append(newRow, c/det)
-		}
-		result = append(result, newRow)
-	}
-	return Transpose(NewMatrix(result)), nil
+	return d.Inverse()
 }
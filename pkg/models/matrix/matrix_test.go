@@ -127,6 +127,14 @@ func TestMatrixEqual(t *testing.T) {
 	}
 }
 
+func TestMatrixEqualWithTolerance(t *testing.T) {
+	a := Translation(1e9, 2, 3)
+	b := Translation(1e9+5, 2, 3)
+	assert.False(t, IsEqual(a, b))
+	assert.True(t, IsEqual(a, b, util.Tolerance{Rel: 1e-6}))
+	assert.False(t, IsEqual(Identity(4), Translation(0, 0, 1e-6), util.Tolerance{}))
+}
+
 func TestMatrixMultiplication(t *testing.T) {
 	a := NewMatrix([][]float64{
 		{1, 2, 3, 4},
@@ -443,3 +451,112 @@ func TestMutliplyingByInverse(t *testing.T) {
 	assert.Nil(t, err)
 	assert.True(t, IsEqual(d, a))
 }
+
+func TestFromRows(t *testing.T) {
+	tests := map[string]struct {
+		rows          [][]float64
+		width, height int
+		err           bool
+	}{
+		"square":    {rows: [][]float64{{1, 2}, {3, 4}}, width: 2, height: 2},
+		"wide":      {rows: [][]float64{{1, 2, 3}, {4, 5, 6}}, width: 3, height: 2},
+		"tall":      {rows: [][]float64{{1}, {2}, {3}}, width: 1, height: 3},
+		"no rows":   {rows: nil, width: 0, height: 0},
+		"ragged":    {rows: [][]float64{{1, 2}, {3}}, err: true},
+		"empty row": {rows: [][]float64{{}}, err: true},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m, err := FromRows(tc.rows)
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.width, m.Width)
+			assert.Equal(t, tc.height, m.Height)
+		})
+	}
+
+	rows := [][]float64{{1, 2}, {3, 4}}
+	m, err := FromRows(rows)
+	assert.NoError(t, err)
+	rows[0][0] = 10
+	got, _ := m.GetElement(0, 0)
+	assert.Equal(t, 1.0, got, "rows should be copied")
+}
+
+func TestZeros(t *testing.T) {
+	m, err := Zeros(2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, m.Width)
+	assert.Equal(t, 2, m.Height)
+	assert.True(t, IsEqual(m, NewMatrix([][]float64{{0, 0, 0}, {0, 0, 0}})))
+
+	m, err = Zeros(0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, m.Width)
+	assert.Equal(t, 0, m.Height)
+
+	for _, dims := range [][2]int{{-1, 2}, {2, -1}, {0, 2}, {2, 0}} {
+		_, err := Zeros(dims[0], dims[1])
+		assert.Error(t, err, "dimensions %v", dims)
+	}
+
+	assert.Equal(t, 0, Identity(-1).Height)
+}
+
+func TestNonSquareAccessors(t *testing.T) {
+	m := NewMatrix([][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+	})
+	assert.Equal(t, 3, m.Width)
+	assert.Equal(t, 2, m.Height)
+
+	got, err := m.GetElement(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 6.0, got)
+	_, err = m.GetElement(2, 1)
+	assert.Error(t, err)
+
+	assert.NoError(t, m.SetElement(0, 2, -3))
+	got, _ = m.GetElement(0, 2)
+	assert.Equal(t, -3.0, got)
+	assert.Error(t, m.SetElement(0, 3, 1))
+	assert.Error(t, m.SetElement(-1, 0, 1))
+
+	row, err := m.Row(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{4, 5, 6}, row)
+	row[0] = 100
+	got, _ = m.GetElement(1, 0)
+	assert.Equal(t, 4.0, got, "row should be a copy")
+	_, err = m.Row(2)
+	assert.Error(t, err)
+
+	col, err := m.Col(2)
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{-3, 6}, col)
+	_, err = m.Col(3)
+	assert.Error(t, err)
+
+	transposed := Transpose(m)
+	assert.Equal(t, 2, transposed.Width)
+	assert.Equal(t, 3, transposed.Height)
+	assert.True(t, IsEqual(transposed, NewMatrix([][]float64{
+		{1, 4},
+		{2, 5},
+		{-3, 6},
+	})))
+}
+
+func TestMatrixString(t *testing.T) {
+	m := NewMatrix([][]float64{
+		{1, -2.5, 3},
+		{10, 0, 0.125},
+	})
+	assert.Equal(t, "|     1  -2.5     3 |\n|    10     0 0.125 |", m.String())
+	assert.Equal(t, "[]", NewMatrix(nil).String())
+}
@@ -0,0 +1,73 @@
+package matrix
+
+import (
+	"errors"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+)
+
+// blockSize is the side of the square tiles multiplyBlocked works on, small
+// enough for a tile of each operand and of the result to stay in L1 cache
+const blockSize = 32
+
+// multiplyBlocked multiplies a by b, where b has cols columns. The loops are
+// tiled and ordered row, inner, column so that the innermost loop walks rows
+// of b and of the result sequentially.
+func multiplyBlocked(a, b [][]float64, cols int) [][]float64 {
+	rows, inner := len(a), len(b)
+	result := make([][]float64, rows)
+	backing := make([]float64, rows*cols)
+	for row := range result {
+		result[row] = backing[row*cols : (row+1)*cols : (row+1)*cols]
+	}
+
+	for row0 := 0; row0 < rows; row0 += blockSize {
+		rowEnd := minInt(row0+blockSize, rows)
+		for i0 := 0; i0 < inner; i0 += blockSize {
+			iEnd := minInt(i0+blockSize, inner)
+			for col0 := 0; col0 < cols; col0 += blockSize {
+				colEnd := minInt(col0+blockSize, cols)
+				for row := row0; row < rowEnd; row++ {
+					out := result[row][col0:colEnd]
+					for i := i0; i < iEnd; i++ {
+						factor := a[row][i]
+						in := b[i][col0:colEnd]
+						for col := range out {
+							out[col] += factor * in[col]
+						}
+					}
+				}
+			}
+		}
+	}
+	return result
+}
+
+// MultiplyVectors multiplies 4x4 matrix m by each of the vectors, which is
+// faster than calling MultiplyByVector in a loop when transforming a whole mesh
+func MultiplyVectors(m *Matrix, vs []*vector.Vector) ([]*vector.Vector, error) {
+	if !is4x4(m) {
+		return nil, errors.New("incompatible dimensions for matrix multiplication")
+	}
+
+	e := m.elements
+	backing := make([]vector.Vector, len(vs))
+	result := make([]*vector.Vector, len(vs))
+	for i, v := range vs {
+		backing[i] = vector.Vector{
+			X: e[0][0]*v.X + e[0][1]*v.Y + e[0][2]*v.Z + e[0][3]*v.W,
+			Y: e[1][0]*v.X + e[1][1]*v.Y + e[1][2]*v.Z + e[1][3]*v.W,
+			Z: e[2][0]*v.X + e[2][1]*v.Y + e[2][2]*v.Z + e[2][3]*v.W,
+			W: e[3][0]*v.X + e[3][1]*v.Y + e[3][2]*v.Z + e[3][3]*v.W,
+		}
+		result[i] = &backing[i]
+	}
+	return result, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
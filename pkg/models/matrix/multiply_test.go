@@ -0,0 +1,157 @@
+package matrix
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiplyNonSquare(t *testing.T) {
+	a := NewMatrix([][]float64{
+		{1, 2, 3},
+		{4, 5, 6},
+	})
+	b := NewMatrix([][]float64{
+		{1, 0, 2, -1},
+		{0, 1, 3, 0},
+		{2, -1, 0, 1},
+	})
+
+	got, err := Multiply(a, b)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, got.Width)
+	assert.Equal(t, 2, got.Height)
+	assert.True(t, IsEqual(got, NewMatrix([][]float64{
+		{7, -1, 8, 2},
+		{16, -1, 23, 2},
+	})))
+
+	_, err = Multiply(b, a)
+	assert.Error(t, err)
+
+	column := NewMatrix([][]float64{{1}, {2}, {3}})
+	got, err = Multiply(a, column)
+	assert.NoError(t, err)
+	assert.True(t, IsEqual(got, NewMatrix([][]float64{{14}, {32}})))
+}
+
+func TestMultiplyBlocked(t *testing.T) {
+	tests := map[string]struct {
+		rows, inner, cols int
+	}{
+		"smaller than a block": {rows: 5, inner: 7, cols: 3},
+		"exact blocks":         {rows: 64, inner: 32, cols: 64},
+		"partial blocks":       {rows: 45, inner: 70, cols: 33},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			a := randomMatrix(r, tc.rows, tc.inner)
+			b := randomMatrix(r, tc.inner, tc.cols)
+
+			got, err := Multiply(a, b)
+			assert.NoError(t, err)
+			assert.True(t, IsEqual(got, naiveMultiply(a, b)))
+		})
+	}
+}
+
+func TestMultiplyVectors(t *testing.T) {
+	m, err := Chain(RotationX(0.5), Scaling(2, 3, 4), Translation(1, -2, 3))
+	assert.NoError(t, err)
+	vs := []*vector.Vector{
+		vector.NewPoint(1, 2, 3),
+		vector.NewVector(-1, 0, 5),
+		vector.NewPoint(0, 0, 0),
+	}
+
+	got, err := MultiplyVectors(m, vs)
+	assert.NoError(t, err)
+	assert.Len(t, got, len(vs))
+	for i, v := range vs {
+		want, err := MultiplyByVector(m, v)
+		assert.NoError(t, err)
+		assert.True(t, vector.Equals(want, got[i]), "vector %v", i)
+	}
+
+	got, err = MultiplyVectors(m, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	_, err = MultiplyVectors(Identity(3), vs)
+	assert.Error(t, err)
+}
+
+func randomMatrix(r *rand.Rand, rows, cols int) *Matrix {
+	m, _ := Zeros(rows, cols)
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			m.elements[row][col] = r.Float64()*2 - 1
+		}
+	}
+	return m
+}
+
+func naiveMultiply(a, b *Matrix) *Matrix {
+	result, _ := Zeros(a.Height, b.Width)
+	for row := 0; row < a.Height; row++ {
+		for col := 0; col < b.Width; col++ {
+			for i := 0; i < a.Width; i++ {
+				result.elements[row][col] += a.elements[row][i] * b.elements[i][col]
+			}
+		}
+	}
+	return result
+}
+
+var (
+	benchMatrix  *Matrix
+	benchVectors []*vector.Vector
+)
+
+func BenchmarkMultiply4x4(b *testing.B) {
+	m := randomMatrix(rand.New(rand.NewSource(1)), 4, 4)
+	for i := 0; i < b.N; i++ {
+		benchMatrix, _ = Multiply(m, m)
+	}
+}
+
+func BenchmarkMultiply256(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	m1, m2 := randomMatrix(r, 256, 256), randomMatrix(r, 256, 256)
+	b.Run("blocked", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchMatrix, _ = Multiply(m1, m2)
+		}
+	})
+	b.Run("naive", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchMatrix = naiveMultiply(m1, m2)
+		}
+	})
+}
+
+func BenchmarkTransformMesh(b *testing.B) {
+	m, _ := Chain(RotationY(0.3), Scaling(2, 2, 2), Translation(1, 2, 3))
+	vs := make([]*vector.Vector, 10000)
+	for i := range vs {
+		vs[i] = vector.NewPoint(float64(i), float64(i%7), float64(i%13))
+	}
+	b.Run("batched", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			benchVectors, _ = MultiplyVectors(m, vs)
+		}
+	})
+	b.Run("loop", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			result := make([]*vector.Vector, len(vs))
+			for j, v := range vs {
+				result[j], _ = MultiplyByVector(m, v)
+			}
+			benchVectors = result
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package matrix
+
+import (
+	"errors"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+)
+
+// TransformPoint applies 4x4 transformation matrix m to point p
+func TransformPoint(m *Matrix, p vector.Point) (vector.Point, error) {
+	if !is4x4(m) {
+		return vector.Point{}, errors.New("transformation matrix must be 4x4")
+	}
+	e := m.elements
+	x := e[0][0]*p.X + e[0][1]*p.Y + e[0][2]*p.Z + e[0][3]
+	y := e[1][0]*p.X + e[1][1]*p.Y + e[1][2]*p.Z + e[1][3]
+	z := e[2][0]*p.X + e[2][1]*p.Y + e[2][2]*p.Z + e[2][3]
+	w := e[3][0]*p.X + e[3][1]*p.Y + e[3][2]*p.Z + e[3][3]
+	if w != 1 && w != 0 {
+		// Projective transformations need the result scaled back to W = 1
+		return vector.Point{X: x / w, Y: y / w, Z: z / w}, nil
+	}
+	return vector.Point{X: x, Y: y, Z: z}, nil
+}
+
+// TransformVec3 applies 4x4 transformation matrix m to direction v, which isn't
+// affected by translation
+func TransformVec3(m *Matrix, v vector.Vec3) (vector.Vec3, error) {
+	if !is4x4(m) {
+		return vector.Vec3{}, errors.New("transformation matrix must be 4x4")
+	}
+	e := m.elements
+	return vector.Vec3{
+		X: e[0][0]*v.X + e[0][1]*v.Y + e[0][2]*v.Z,
+		Y: e[1][0]*v.X + e[1][1]*v.Y + e[1][2]*v.Z,
+		Z: e[2][0]*v.X + e[2][1]*v.Y + e[2][2]*v.Z,
+	}, nil
+}
+
+// NormalMatrix returns the inverse transpose of 4x4 matrix m, which transforms
+// normals of a surface transformed by m so that they stay perpendicular to it.
+// It's meant to be computed once per object, normals are then transformed with
+// TransformVec3 and normalized.
+func NormalMatrix(m *Matrix) (*Matrix, error) {
+	if !is4x4(m) {
+		return nil, errors.New("transformation matrix must be 4x4")
+	}
+	inverse, err := GetInverse(m)
+	if err != nil {
+		return nil, err
+	}
+	return Transpose(inverse), nil
+}
+
+// TransformNormal transforms normal n of a surface transformed by m and returns
+// it normalized. It inverts m on every call, see NormalMatrix for transforming
+// many normals.
+func TransformNormal(m *Matrix, n vector.Normal) (vector.Normal, error) {
+	nm, err := NormalMatrix(m)
+	if err != nil {
+		return vector.Normal{}, err
+	}
+	v, err := TransformVec3(nm, n.Vec3())
+	if err != nil {
+		return vector.Normal{}, err
+	}
+	return v.Normal()
+}
+
+func is4x4(m *Matrix) bool {
+	return m.Width == 4 && m.Height == 4
+}
@@ -0,0 +1,76 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformPointAndVec3(t *testing.T) {
+	m, err := Chain(Scaling(2, 2, 2), Translation(1, 2, 3))
+	assert.Nil(t, err)
+
+	p, err := TransformPoint(m, vector.Point{X: 1, Y: 1, Z: 1})
+	assert.Nil(t, err)
+	assert.True(t, p.Equals(vector.Point{X: 3, Y: 4, Z: 5}))
+
+	// Directions aren't moved by translation
+	v, err := TransformVec3(m, vector.Vec3{X: 1, Y: 1, Z: 1})
+	assert.Nil(t, err)
+	assert.True(t, v.Equals(vector.Vec3{X: 2, Y: 2, Z: 2}))
+
+	// Results match the 4D Vector API
+	want, err := MultiplyByVector(m, vector.NewPoint(1, 1, 1))
+	assert.Nil(t, err)
+	assert.Equal(t, *want, p.Vector())
+
+	_, err = TransformPoint(Identity(3), vector.Point{})
+	assert.NotNil(t, err)
+	_, err = TransformVec3(Identity(3), vector.Vec3{})
+	assert.NotNil(t, err)
+}
+
+func TestTransformNormal(t *testing.T) {
+	tests := map[string]struct {
+		m      *Matrix
+		normal vector.Normal
+		want   vector.Normal
+	}{
+		"translated": {
+			m:      Translation(0, 1, 0),
+			normal: vector.Normal{X: 0, Y: 0.70711, Z: -0.70711},
+			want:   vector.Normal{X: 0, Y: 0.70711, Z: -0.70711},
+		},
+		// Squashing the surface makes its normal steeper, not flatter like a
+		// vector lying in the surface
+		"scaled": {
+			m:      Scaling(1, 0.5, 1),
+			normal: vector.Normal{X: 0, Y: 0.70711, Z: -0.70711},
+			want:   vector.Normal{X: 0, Y: 0.89443, Z: -0.44721},
+		},
+		"rotated": {
+			m:      RotationZ(math.Pi / 2),
+			normal: vector.Normal{X: 1, Y: 0, Z: 0},
+			want:   vector.Normal{X: 0, Y: 1, Z: 0},
+		},
+	}
+	for name, tc := range tests {
+		got, err := TransformNormal(tc.m, tc.normal)
+		assert.Nil(t, err, name)
+		assert.True(t, got.Equals(tc.want), "%s: expected %v, got %v", name, tc.want, got)
+	}
+
+	// Normal stays perpendicular to a tangent of a non-uniformly scaled surface
+	m := Scaling(1, 4, 1)
+	tangent, _ := TransformVec3(m, vector.Vec3{X: 1, Y: -1, Z: 0})
+	n, err := TransformNormal(m, vector.Normal{X: 1, Y: 1, Z: 0})
+	assert.Nil(t, err)
+	assert.InDelta(t, 0, n.Dot(tangent), 1e-9)
+
+	_, err = TransformNormal(Scaling(0, 1, 1), vector.Normal{X: 1})
+	assert.NotNil(t, err)
+	_, err = NormalMatrix(Identity(2))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,121 @@
+package matrix
+
+import (
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+)
+
+// Identity returns an n x n identity matrix, or an empty matrix if n isn't
+// positive
+func Identity(n int) *Matrix {
+	if n <= 0 {
+		return NewMatrix(nil)
+	}
+	result := make([][]float64, n)
+	for row := range result {
+		result[row] = make([]float64, n)
+		result[row][row] = 1
+	}
+	return NewMatrix(result)
+}
+
+// Translation returns a 4x4 matrix that moves points by (x, y, z) and leaves
+// vectors unchanged
+func Translation(x, y, z float64) *Matrix {
+	return NewMatrix([][]float64{
+		{1, 0, 0, x},
+		{0, 1, 0, y},
+		{0, 0, 1, z},
+		{0, 0, 0, 1},
+	})
+}
+
+// Scaling returns a 4x4 matrix that scales every axis by its own factor
+func Scaling(x, y, z float64) *Matrix {
+	return NewMatrix([][]float64{
+		{x, 0, 0, 0},
+		{0, y, 0, 0},
+		{0, 0, z, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// RotationX returns a 4x4 matrix that rotates around the X axis by r radians
+func RotationX(r float64) *Matrix {
+	cos, sin := math.Cos(r), math.Sin(r)
+	return NewMatrix([][]float64{
+		{1, 0, 0, 0},
+		{0, cos, -sin, 0},
+		{0, sin, cos, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// RotationY returns a 4x4 matrix that rotates around the Y axis by r radians
+func RotationY(r float64) *Matrix {
+	cos, sin := math.Cos(r), math.Sin(r)
+	return NewMatrix([][]float64{
+		{cos, 0, sin, 0},
+		{0, 1, 0, 0},
+		{-sin, 0, cos, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// RotationZ returns a 4x4 matrix that rotates around the Z axis by r radians
+func RotationZ(r float64) *Matrix {
+	cos, sin := math.Cos(r), math.Sin(r)
+	return NewMatrix([][]float64{
+		{cos, -sin, 0, 0},
+		{sin, cos, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// Shearing returns a 4x4 matrix that moves every coordinate in proportion to the
+// other two, e.g. xy is how much x changes in proportion to y
+func Shearing(xy, xz, yx, yz, zx, zy float64) *Matrix {
+	return NewMatrix([][]float64{
+		{1, xy, xz, 0},
+		{yx, 1, yz, 0},
+		{zx, zy, 1, 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// ViewTransform returns a matrix that orients the world relative to the eye
+// located at point from, looking at point to, with up pointing approximately up
+func ViewTransform(from, to, up *vector.Vector) (*Matrix, error) {
+	forward, err := vector.Normalize(vector.Subtract(to, from))
+	if err != nil {
+		return nil, err
+	}
+	upn, err := vector.Normalize(up)
+	if err != nil {
+		return nil, err
+	}
+	left := vector.Cross(forward, upn)
+	trueUp := vector.Cross(left, forward)
+	orientation := NewMatrix([][]float64{
+		{left.X, left.Y, left.Z, 0},
+		{trueUp.X, trueUp.Y, trueUp.Z, 0},
+		{-forward.X, -forward.Y, -forward.Z, 0},
+		{0, 0, 0, 1},
+	})
+	return Multiply(orientation, Translation(-from.X, -from.Y, -from.Z))
+}
+
+// Chain multiplies transformations in the order they should be applied, so
+// Chain(a, b, c) is c * b * a. It returns an identity matrix if there are none.
+func Chain(transforms ...*Matrix) (*Matrix, error) {
+	result := Identity(4)
+	for _, t := range transforms {
+		var err error
+		if result, err = Multiply(t, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
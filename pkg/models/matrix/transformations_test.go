@@ -0,0 +1,102 @@
+package matrix
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentity(t *testing.T) {
+	assert.True(t, IsEqual(Identity(2), NewMatrix([][]float64{
+		{1, 0},
+		{0, 1},
+	})))
+	assert.True(t, IsEqual(Identity(0), NewMatrix([][]float64{})))
+}
+
+func TestTransformations(t *testing.T) {
+	tests := map[string]struct {
+		m     *Matrix
+		input *vector.Vector
+		want  *vector.Vector
+	}{
+		"translate point":    {m: Translation(5, -3, 2), input: vector.NewPoint(-3, 4, 5), want: vector.NewPoint(2, 1, 7)},
+		"translate vector":   {m: Translation(5, -3, 2), input: vector.NewVector(-3, 4, 5), want: vector.NewVector(-3, 4, 5)},
+		"scale point":        {m: Scaling(2, 3, 4), input: vector.NewPoint(-4, 6, 8), want: vector.NewPoint(-8, 18, 32)},
+		"scale vector":       {m: Scaling(2, 3, 4), input: vector.NewVector(-4, 6, 8), want: vector.NewVector(-8, 18, 32)},
+		"reflect":            {m: Scaling(-1, 1, 1), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(-2, 3, 4)},
+		"rotate x":           {m: RotationX(math.Pi / 4), input: vector.NewPoint(0, 1, 0), want: vector.NewPoint(0, math.Sqrt2/2, math.Sqrt2/2)},
+		"rotate x full":      {m: RotationX(math.Pi / 2), input: vector.NewPoint(0, 1, 0), want: vector.NewPoint(0, 0, 1)},
+		"rotate y":           {m: RotationY(math.Pi / 2), input: vector.NewPoint(0, 0, 1), want: vector.NewPoint(1, 0, 0)},
+		"rotate z":           {m: RotationZ(math.Pi / 2), input: vector.NewPoint(0, 1, 0), want: vector.NewPoint(-1, 0, 0)},
+		"shear x to y":       {m: Shearing(1, 0, 0, 0, 0, 0), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(5, 3, 4)},
+		"shear x to z":       {m: Shearing(0, 1, 0, 0, 0, 0), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(6, 3, 4)},
+		"shear y to x":       {m: Shearing(0, 0, 1, 0, 0, 0), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(2, 5, 4)},
+		"shear y to z":       {m: Shearing(0, 0, 0, 1, 0, 0), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(2, 7, 4)},
+		"shear z to x":       {m: Shearing(0, 0, 0, 0, 1, 0), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(2, 3, 6)},
+		"shear z to y":       {m: Shearing(0, 0, 0, 0, 0, 1), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(2, 3, 7)},
+		"identity transform": {m: Identity(4), input: vector.NewPoint(2, 3, 4), want: vector.NewPoint(2, 3, 4)},
+	}
+
+	for name, tc := range tests {
+		got, err := MultiplyByVector(tc.m, tc.input)
+		assert.Nil(t, err, name)
+		if !vector.Equals(got, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestChain(t *testing.T) {
+	chained, err := Chain(RotationX(math.Pi/2), Scaling(5, 5, 5), Translation(10, 5, 7))
+	assert.Nil(t, err)
+	got, err := MultiplyByVector(chained, vector.NewPoint(1, 0, 1))
+	assert.Nil(t, err)
+	assert.True(t, vector.Equals(got, vector.NewPoint(15, 0, 7)))
+
+	empty, err := Chain()
+	assert.Nil(t, err)
+	assert.True(t, IsEqual(empty, Identity(4)))
+}
+
+func TestViewTransform(t *testing.T) {
+	tests := map[string]struct {
+		from, to, up *vector.Vector
+		want         *Matrix
+	}{
+		"default orientation": {
+			from: vector.NewPoint(0, 0, 0), to: vector.NewPoint(0, 0, -1), up: vector.NewVector(0, 1, 0),
+			want: Identity(4),
+		},
+		"looking in positive z": {
+			from: vector.NewPoint(0, 0, 0), to: vector.NewPoint(0, 0, 1), up: vector.NewVector(0, 1, 0),
+			want: Scaling(-1, 1, -1),
+		},
+		"moves the world": {
+			from: vector.NewPoint(0, 0, 8), to: vector.NewPoint(0, 0, 0), up: vector.NewVector(0, 1, 0),
+			want: Translation(0, 0, -8),
+		},
+		"arbitrary": {
+			from: vector.NewPoint(1, 3, 2), to: vector.NewPoint(4, -2, 8), up: vector.NewVector(1, 1, 0),
+			want: NewMatrix([][]float64{
+				{-0.50709, 0.50709, 0.67612, -2.36643},
+				{0.76772, 0.60609, 0.12122, -2.82843},
+				{-0.35857, 0.59761, -0.71714, 0},
+				{0, 0, 0, 1},
+			}),
+		},
+	}
+
+	for name, tc := range tests {
+		got, err := ViewTransform(tc.from, tc.to, tc.up)
+		assert.Nil(t, err, name)
+		if !IsEqual(got, tc.want) {
+			t.Fatalf("%s: expected %v, got %v", name, tc.want, got)
+		}
+	}
+
+	_, err := ViewTransform(vector.NewPoint(1, 1, 1), vector.NewPoint(1, 1, 1), vector.NewVector(0, 1, 0))
+	assert.NotNil(t, err)
+}
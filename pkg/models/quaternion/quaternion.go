@@ -0,0 +1,186 @@
+package quaternion
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+)
+
+// Quaternion represents a rotation as W + Xi + Yj + Zk. Only unit quaternions
+// are rotations, functions below that produce rotations always return those.
+type Quaternion struct {
+	W, X, Y, Z float64
+}
+
+// NewQuaternion creates a quaternion from its components
+func NewQuaternion(w, x, y, z float64) *Quaternion {
+	return &Quaternion{W: w, X: x, Y: y, Z: z}
+}
+
+// Identity returns the quaternion that doesn't rotate anything
+func Identity() *Quaternion {
+	return &Quaternion{W: 1}
+}
+
+// FromAxisAngle returns rotation by angle radians around axis, counterclockwise
+// when looking from the tip of the axis like matrix.RotationX/Y/Z
+func FromAxisAngle(axis *vector.Vector, angle float64) (*Quaternion, error) {
+	n, err := vector.Normalize(vector.NewVector(axis.X, axis.Y, axis.Z))
+	if err != nil {
+		return nil, errors.New("rotation axis can't be zero")
+	}
+	sin := math.Sin(angle / 2)
+	return &Quaternion{W: math.Cos(angle / 2), X: n.X * sin, Y: n.Y * sin, Z: n.Z * sin}, nil
+}
+
+// Equals compares two quaternions for equality using the optional tolerance. q
+// and -q are the same rotation, but they aren't equal.
+func Equals(q1, q2 *Quaternion, tol ...util.Tolerance) bool {
+	t := util.ToleranceOrDefault(tol...)
+	return t.Equals(q1.W, q2.W) && t.Equals(q1.X, q2.X) &&
+		t.Equals(q1.Y, q2.Y) && t.Equals(q1.Z, q2.Z)
+}
+
+// Multiply returns the Hamilton product of q1 and q2, which is the rotation by
+// q2 followed by q1
+func Multiply(q1, q2 *Quaternion) *Quaternion {
+	return &Quaternion{
+		W: q1.W*q2.W - q1.X*q2.X - q1.Y*q2.Y - q1.Z*q2.Z,
+		X: q1.W*q2.X + q1.X*q2.W + q1.Y*q2.Z - q1.Z*q2.Y,
+		Y: q1.W*q2.Y - q1.X*q2.Z + q1.Y*q2.W + q1.Z*q2.X,
+		Z: q1.W*q2.Z + q1.X*q2.Y - q1.Y*q2.X + q1.Z*q2.W,
+	}
+}
+
+// Conjugate returns q with the vector part negated, which for unit quaternions
+// is the inverse rotation
+func Conjugate(q *Quaternion) *Quaternion {
+	return &Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Dot returns the dot product of q1 and q2
+func Dot(q1, q2 *Quaternion) float64 {
+	return q1.W*q2.W + q1.X*q2.X + q1.Y*q2.Y + q1.Z*q2.Z
+}
+
+// Magnitude returns the length of q
+func Magnitude(q *Quaternion) float64 {
+	return math.Sqrt(Dot(q, q))
+}
+
+// Normalize scales q to unit length, which turns it into a rotation
+func Normalize(q *Quaternion) (*Quaternion, error) {
+	m := Magnitude(q)
+	if util.FloatEquals(m, 0) {
+		return nil, errors.New("attempt to normalize zero quaternion")
+	}
+	return &Quaternion{W: q.W / m, X: q.X / m, Y: q.Y / m, Z: q.Z / m}, nil
+}
+
+// Slerp interpolates with constant angular speed between rotations q1 for t = 0
+// and q2 for t = 1, always taking the shorter way around
+func Slerp(q1, q2 *Quaternion, t float64) *Quaternion {
+	end := *q2
+	cos := Dot(q1, q2)
+	if cos < 0 {
+		end = Quaternion{W: -q2.W, X: -q2.X, Y: -q2.Y, Z: -q2.Z}
+		cos = -cos
+	}
+
+	// Sine of a tiny angle would lose precision, but linear interpolation is just
+	// as good there
+	a, b := 1-t, t
+	if cos < 0.9995 {
+		angle := math.Acos(cos)
+		sin := math.Sin(angle)
+		a, b = math.Sin((1-t)*angle)/sin, math.Sin(t*angle)/sin
+	}
+	result := &Quaternion{
+		W: a*q1.W + b*end.W,
+		X: a*q1.X + b*end.X,
+		Y: a*q1.Y + b*end.Y,
+		Z: a*q1.Z + b*end.Z,
+	}
+	if normalized, err := Normalize(result); err == nil {
+		return normalized
+	}
+	return result
+}
+
+// Rotate rotates v by unit quaternion q. W of v is kept, so points stay points.
+func Rotate(q *Quaternion, v *vector.Vector) *vector.Vector {
+	p := Multiply(Multiply(q, &Quaternion{X: v.X, Y: v.Y, Z: v.Z}), Conjugate(q))
+	return vector.New4DVector(p.X, p.Y, p.Z, v.W)
+}
+
+// ToMatrix returns the 4x4 rotation matrix of unit quaternion q
+func ToMatrix(q *Quaternion) *matrix.Matrix {
+	w, x, y, z := q.W, q.X, q.Y, q.Z
+	return matrix.NewMatrix([][]float64{
+		{1 - 2*(y*y+z*z), 2 * (x*y - w*z), 2 * (x*z + w*y), 0},
+		{2 * (x*y + w*z), 1 - 2*(x*x+z*z), 2 * (y*z - w*x), 0},
+		{2 * (x*z - w*y), 2 * (y*z + w*x), 1 - 2*(x*x+y*y), 0},
+		{0, 0, 0, 1},
+	})
+}
+
+// FromMatrix returns the rotation of 4x4 matrix m or error if its upper 3x3 part
+// isn't a pure rotation, e.g. it scales or mirrors. Translation is ignored.
+func FromMatrix(m *matrix.Matrix) (*Quaternion, error) {
+	if m.Width != 4 || m.Height != 4 {
+		return nil, errors.New("rotation matrix must be 4x4")
+	}
+	var e [3][3]float64
+	for row := range e {
+		for col := range e[row] {
+			e[row][col], _ = m.GetElement(row, col)
+		}
+	}
+
+	if !isRotation(e) {
+		return nil, errors.New("matrix is not a rotation")
+	}
+
+	// Shepperd's method divides by the largest of the four possible
+	// denominators, which keeps it stable for every rotation
+	var q Quaternion
+	trace := e[0][0] + e[1][1] + e[2][2]
+	switch {
+	case trace > 0:
+		s := 2 * math.Sqrt(1+trace)
+		q = Quaternion{W: s / 4, X: (e[2][1] - e[1][2]) / s, Y: (e[0][2] - e[2][0]) / s, Z: (e[1][0] - e[0][1]) / s}
+	case e[0][0] > e[1][1] && e[0][0] > e[2][2]:
+		s := 2 * math.Sqrt(1+e[0][0]-e[1][1]-e[2][2])
+		q = Quaternion{W: (e[2][1] - e[1][2]) / s, X: s / 4, Y: (e[0][1] + e[1][0]) / s, Z: (e[0][2] + e[2][0]) / s}
+	case e[1][1] > e[2][2]:
+		s := 2 * math.Sqrt(1+e[1][1]-e[0][0]-e[2][2])
+		q = Quaternion{W: (e[0][2] - e[2][0]) / s, X: (e[0][1] + e[1][0]) / s, Y: s / 4, Z: (e[1][2] + e[2][1]) / s}
+	default:
+		s := 2 * math.Sqrt(1+e[2][2]-e[0][0]-e[1][1])
+		q = Quaternion{W: (e[1][0] - e[0][1]) / s, X: (e[0][2] + e[2][0]) / s, Y: (e[1][2] + e[2][1]) / s, Z: s / 4}
+	}
+	return Normalize(&q)
+}
+
+// isRotation checks that rows of e are orthonormal and that it doesn't mirror
+func isRotation(e [3][3]float64) bool {
+	for i := range e {
+		for j := range e {
+			dot := e[i][0]*e[j][0] + e[i][1]*e[j][1] + e[i][2]*e[j][2]
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			if !util.FloatEquals(dot, want) {
+				return false
+			}
+		}
+	}
+	det := e[0][0]*(e[1][1]*e[2][2]-e[1][2]*e[2][1]) -
+		e[0][1]*(e[1][0]*e[2][2]-e[1][2]*e[2][0]) +
+		e[0][2]*(e[1][0]*e[2][1]-e[1][1]*e[2][0])
+	return det > 0
+}
@@ -0,0 +1,144 @@
+package quaternion
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromAxisAngle(t *testing.T) {
+	q, err := FromAxisAngle(vector.NewVector(0, 0, 2), math.Pi/2)
+	assert.Nil(t, err)
+	assert.True(t, Equals(q, NewQuaternion(math.Sqrt2/2, 0, 0, math.Sqrt2/2)))
+	assert.InDelta(t, 1, Magnitude(q), 1e-12)
+
+	_, err = FromAxisAngle(vector.NewVector(0, 0, 0), 1)
+	assert.NotNil(t, err)
+}
+
+func TestRotateMatchesMatrices(t *testing.T) {
+	tests := map[string]struct {
+		axis *vector.Vector
+		m    *matrix.Matrix
+	}{
+		"x": {axis: vector.NewVector(1, 0, 0), m: matrix.RotationX(math.Pi / 3)},
+		"y": {axis: vector.NewVector(0, 1, 0), m: matrix.RotationY(math.Pi / 3)},
+		"z": {axis: vector.NewVector(0, 0, 1), m: matrix.RotationZ(math.Pi / 3)},
+	}
+
+	p := vector.NewPoint(1, -2, 3)
+	for name, tc := range tests {
+		q, err := FromAxisAngle(tc.axis, math.Pi/3)
+		assert.Nil(t, err)
+		want, err := matrix.MultiplyByVector(tc.m, p)
+		assert.Nil(t, err)
+		got := Rotate(q, p)
+		if !vector.Equals(got, want) {
+			t.Fatalf("%s: expected: %v, got %v", name, want, got)
+		}
+		assert.True(t, matrix.IsEqual(ToMatrix(q), tc.m), name)
+	}
+}
+
+func TestMultiplyComposesRotations(t *testing.T) {
+	qx, _ := FromAxisAngle(vector.NewVector(1, 0, 0), math.Pi/2)
+	qy, _ := FromAxisAngle(vector.NewVector(0, 1, 0), math.Pi/2)
+	v := vector.NewVector(0, 0, 1)
+
+	// Rotation by qx first and then by qy
+	combined := Multiply(qy, qx)
+	assert.True(t, vector.Equals(Rotate(combined, v), Rotate(qy, Rotate(qx, v))))
+	assert.True(t, vector.Equals(Rotate(combined, v), vector.NewVector(0, -1, 0)))
+
+	// Conjugate undoes the rotation
+	assert.True(t, Equals(Multiply(combined, Conjugate(combined)), Identity()))
+}
+
+func TestNormalize(t *testing.T) {
+	q, err := Normalize(NewQuaternion(1, 1, 1, 1))
+	assert.Nil(t, err)
+	assert.True(t, Equals(q, NewQuaternion(0.5, 0.5, 0.5, 0.5)))
+	_, err = Normalize(NewQuaternion(0, 0, 0, 0))
+	assert.NotNil(t, err)
+}
+
+func TestEqualsWithTolerance(t *testing.T) {
+	q1 := NewQuaternion(1, 0, 0, 0)
+	q2 := NewQuaternion(0.999, 0, 0, 0)
+	assert.False(t, Equals(q1, q2))
+	assert.True(t, Equals(q1, q2, util.Tolerance{Abs: 0.01}))
+	assert.False(t, Equals(q1, NewQuaternion(1+1e-9, 0, 0, 0), util.Tolerance{}))
+}
+
+func TestSlerp(t *testing.T) {
+	axis := vector.NewVector(0, 1, 0)
+	start, _ := FromAxisAngle(axis, 0)
+	end, _ := FromAxisAngle(axis, math.Pi/2)
+
+	tests := map[string]struct {
+		t     float64
+		angle float64
+	}{
+		"start":   {t: 0, angle: 0},
+		"quarter": {t: 0.25, angle: math.Pi / 8},
+		"half":    {t: 0.5, angle: math.Pi / 4},
+		"end":     {t: 1, angle: math.Pi / 2},
+	}
+	for name, tc := range tests {
+		want, _ := FromAxisAngle(axis, tc.angle)
+		got := Slerp(start, end, tc.t)
+		if !Equals(got, want) {
+			t.Fatalf("%s: expected: %v, got %v", name, want, got)
+		}
+	}
+
+	// -end is the same rotation, slerp still takes the short way
+	negated := NewQuaternion(-end.W, -end.X, -end.Y, -end.Z)
+	half, _ := FromAxisAngle(axis, math.Pi/4)
+	assert.True(t, Equals(Slerp(start, negated, 0.5), half))
+
+	// Almost identical rotations don't produce NaNs
+	near, _ := FromAxisAngle(axis, 1e-9)
+	got := Slerp(start, near, 0.5)
+	assert.False(t, math.IsNaN(got.W))
+	assert.InDelta(t, 1, Magnitude(got), 1e-12)
+}
+
+func TestFromMatrix(t *testing.T) {
+	tests := map[string]*vector.Vector{
+		"around x":        vector.NewVector(1, 0, 0),
+		"around y":        vector.NewVector(0, 1, 0),
+		"around z":        vector.NewVector(0, 0, 1),
+		"around diagonal": vector.NewVector(1, 1, -1),
+	}
+
+	for name, axis := range tests {
+		// Angles near pi exercise the branches for a negative trace
+		for _, angle := range []float64{0.3, 2, math.Pi - 0.01} {
+			q, _ := FromAxisAngle(axis, angle)
+			got, err := FromMatrix(ToMatrix(q))
+			assert.Nil(t, err, name)
+			if !Equals(got, q) && !Equals(got, NewQuaternion(-q.W, -q.X, -q.Y, -q.Z)) {
+				t.Fatalf("%s by %v: expected: %v, got %v", name, angle, q, got)
+			}
+		}
+	}
+
+	// Translation is ignored
+	m, _ := matrix.Chain(matrix.RotationZ(1), matrix.Translation(1, 2, 3))
+	q, err := FromMatrix(m)
+	assert.Nil(t, err)
+	want, _ := FromAxisAngle(vector.NewVector(0, 0, 1), 1)
+	assert.True(t, Equals(q, want))
+
+	_, err = FromMatrix(matrix.Identity(3))
+	assert.NotNil(t, err)
+	_, err = FromMatrix(matrix.Scaling(2, 1, 1))
+	assert.NotNil(t, err)
+	_, err = FromMatrix(matrix.Scaling(-1, 1, 1))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,38 @@
+// Package ray implements rays, half-lines going from an origin point in some
+// direction, which are traced through the scene to find what is visible.
+package ray
+
+import (
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+)
+
+// Ray starts at point Origin and goes along vector Direction
+type Ray struct {
+	Origin, Direction *vector.Vector
+}
+
+// NewRay creates a new Ray given its origin point and direction vector
+func NewRay(origin, direction *vector.Vector) *Ray {
+	return &Ray{origin, direction}
+}
+
+// Position returns the point at distance t along the ray, measured in lengths
+// of its direction
+func Position(r *Ray, t float64) *vector.Vector {
+	return vector.Add(r.Origin, vector.Multiply(r.Direction, t))
+}
+
+// Transform applies 4x4 transformation matrix m to both the origin and the
+// direction of r and returns the resulting Ray
+func Transform(r *Ray, m *matrix.Matrix) (*Ray, error) {
+	origin, err := matrix.MultiplyByVector(m, r.Origin)
+	if err != nil {
+		return nil, err
+	}
+	direction, err := matrix.MultiplyByVector(m, r.Direction)
+	if err != nil {
+		return nil, err
+	}
+	return NewRay(origin, direction), nil
+}
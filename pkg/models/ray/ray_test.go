@@ -0,0 +1,65 @@
+package ray
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPosition(t *testing.T) {
+	r := NewRay(vector.NewPoint(2, 3, 4), vector.NewVector(1, 0, 0))
+	tests := map[string]struct {
+		t    float64
+		want *vector.Vector
+	}{
+		"origin":   {t: 0, want: vector.NewPoint(2, 3, 4)},
+		"forward":  {t: 1, want: vector.NewPoint(3, 3, 4)},
+		"backward": {t: -1, want: vector.NewPoint(1, 3, 4)},
+		"fraction": {t: 2.5, want: vector.NewPoint(4.5, 3, 4)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.True(t, vector.Equals(tc.want, Position(r, tc.t)))
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	r := NewRay(vector.NewPoint(1, 2, 3), vector.NewVector(0, 1, 0))
+	tests := map[string]struct {
+		m                 *matrix.Matrix
+		origin, direction *vector.Vector
+	}{
+		"translation": {
+			m:         matrix.Translation(3, 4, 5),
+			origin:    vector.NewPoint(4, 6, 8),
+			direction: vector.NewVector(0, 1, 0),
+		},
+		"scaling": {
+			m:         matrix.Scaling(2, 3, 4),
+			origin:    vector.NewPoint(2, 6, 12),
+			direction: vector.NewVector(0, 3, 0),
+		},
+		"rotation": {
+			m:         matrix.RotationZ(math.Pi / 2),
+			origin:    vector.NewPoint(-2, 1, 3),
+			direction: vector.NewVector(-1, 0, 0),
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Transform(r, tc.m)
+			assert.NoError(t, err)
+			assert.True(t, vector.Equals(tc.origin, got.Origin), "origin %v", got.Origin)
+			assert.True(t, vector.Equals(tc.direction, got.Direction), "direction %v", got.Direction)
+		})
+	}
+
+	_, err := Transform(r, matrix.Identity(3))
+	assert.Error(t, err)
+}
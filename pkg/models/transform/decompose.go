@@ -0,0 +1,151 @@
+// Package transform splits transformation matrices into the components they are
+// made of and puts them back together.
+package transform
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/quaternion"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+)
+
+// Components of an affine transformation, which are applied in the order scale,
+// shear, rotation and translation
+type Components struct {
+	Translation *vector.Vector
+	Rotation    *quaternion.Quaternion
+	// Scale is negative along X for transformations that mirror the space
+	Scale *vector.Vector
+	Shear Shear
+}
+
+// Shear holds the factors of matrix.Shearing that are left after rotation is
+// taken out, e.g. XY is how much x changes in proportion to y
+type Shear struct {
+	XY, XZ, YZ float64
+}
+
+// Decompose splits 4x4 affine matrix m into components, so that Recompose of
+// the result gives m back. It returns error if m is projective or singular.
+func Decompose(m *matrix.Matrix) (*Components, error) {
+	if m.Width != 4 || m.Height != 4 {
+		return nil, errors.New("transformation matrix must be 4x4")
+	}
+	var e [4][4]float64
+	for row := range e {
+		for col := range e[row] {
+			e[row][col], _ = m.GetElement(row, col)
+		}
+	}
+	if !util.FloatEquals(e[3][0], 0) || !util.FloatEquals(e[3][1], 0) ||
+		!util.FloatEquals(e[3][2], 0) || !util.FloatEquals(e[3][3], 1) {
+		return nil, errors.New("projective matrix can't be decomposed")
+	}
+
+	// Columns are the images of the axes, Gram-Schmidt turns them into rotated
+	// axes and an upper triangular matrix of scale and shear
+	col := func(i int) vector.Vector {
+		return vector.Vector{X: e[0][i], Y: e[1][i], Z: e[2][i]}
+	}
+	c0, c1, c2 := col(0), col(1), col(2)
+	norm := math.Max(c0.Magnitude(), math.Max(c1.Magnitude(), c2.Magnitude()))
+
+	r0, sx, err := axis(c0, norm)
+	if err != nil {
+		return nil, err
+	}
+	xy := r0.Dot(c1)
+	c1 = c1.Subtract(r0.Multiply(xy))
+	r1, sy, err := axis(c1, norm)
+	if err != nil {
+		return nil, err
+	}
+	xz, yz := r0.Dot(c2), r1.Dot(c2)
+	c2 = c2.Subtract(r0.Multiply(xz)).Subtract(r1.Multiply(yz))
+	r2, sz, err := axis(c2, norm)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mirrored axes would make the rotation improper, so the mirroring is moved
+	// to the X scale instead
+	if r0.Dot(r1.Cross(r2)) < 0 {
+		r0 = r0.Negate()
+		sx, xy, xz = -sx, -xy, -xz
+	}
+
+	rotation, err := quaternion.FromMatrix(matrix.NewMatrix([][]float64{
+		{r0.X, r1.X, r2.X, 0},
+		{r0.Y, r1.Y, r2.Y, 0},
+		{r0.Z, r1.Z, r2.Z, 0},
+		{0, 0, 0, 1},
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &Components{
+		Translation: vector.NewVector(e[0][3], e[1][3], e[2][3]),
+		Rotation:    rotation,
+		Scale:       vector.NewVector(sx, sy, sz),
+		Shear:       Shear{XY: xy / sy, XZ: xz / sz, YZ: yz / sz},
+	}, nil
+}
+
+// axis normalizes column v left after Gram-Schmidt and returns its magnitude.
+// Whether v is zero is decided relative to norm, the largest column of the
+// matrix, so that uniformly tiny scales aren't mistaken for singular matrices.
+func axis(v vector.Vector, norm float64) (vector.Vector, float64, error) {
+	m := v.Magnitude()
+	if m == 0 || m <= util.DefaultTolerance.Rel*norm {
+		return vector.Vector{}, 0, errors.New("matrix is singular")
+	}
+	return v.Multiply(1 / m), m, nil
+}
+
+// Recompose builds the matrix that applies components c
+func Recompose(c *Components) *matrix.Matrix {
+	m, _ := matrix.Chain(
+		matrix.Scaling(c.Scale.X, c.Scale.Y, c.Scale.Z),
+		matrix.Shearing(c.Shear.XY, c.Shear.XZ, 0, c.Shear.YZ, 0, 0),
+		quaternion.ToMatrix(c.Rotation),
+		matrix.Translation(c.Translation.X, c.Translation.Y, c.Translation.Z),
+	)
+	return m
+}
+
+// Interpolate blends components a for t = 0 and b for t = 1, rotation is
+// interpolated with quaternion.Slerp and everything else linearly
+func Interpolate(a, b *Components, t float64) *Components {
+	lerp := func(x, y float64) float64 { return x + (y-x)*t }
+	return &Components{
+		Translation: vector.Lerp(a.Translation, b.Translation, t),
+		Rotation:    quaternion.Slerp(a.Rotation, b.Rotation, t),
+		Scale:       vector.Lerp(a.Scale, b.Scale, t),
+		Shear: Shear{
+			XY: lerp(a.Shear.XY, b.Shear.XY),
+			XZ: lerp(a.Shear.XZ, b.Shear.XZ),
+			YZ: lerp(a.Shear.YZ, b.Shear.YZ),
+		},
+	}
+}
+
+// EulerAngles returns angles x, y and z in radians, such that the rotation q is
+// the same as matrix.Chain(RotationX(x), RotationY(y), RotationZ(z)). Y is kept
+// in [-pi/2, pi/2], and when it's at either end, the rotation around X and Z
+// can't be told apart, so all of it is returned as x.
+func EulerAngles(q *quaternion.Quaternion) (x, y, z float64) {
+	m := quaternion.ToMatrix(q)
+	e := func(row, col int) float64 {
+		v, _ := m.GetElement(row, col)
+		return v
+	}
+	sinY := math.Max(-1, math.Min(1, -e(2, 0)))
+	y = math.Asin(sinY)
+	if math.Abs(sinY) > 1-1e-9 {
+		return math.Atan2(sinY*e(0, 1), e(1, 1)), y, 0
+	}
+	return math.Atan2(e(2, 1), e(2, 2)), y, math.Atan2(e(1, 0), e(0, 0))
+}
@@ -0,0 +1,147 @@
+package transform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/quaternion"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func chain(t *testing.T, transforms ...*matrix.Matrix) *matrix.Matrix {
+	t.Helper()
+	m, err := matrix.Chain(transforms...)
+	assert.Nil(t, err)
+	return m
+}
+
+func TestDecomposeComponents(t *testing.T) {
+	m := chain(t, matrix.Scaling(2, 3, 4), matrix.RotationY(math.Pi/3), matrix.Translation(1, -2, 5))
+	c, err := Decompose(m)
+	assert.Nil(t, err)
+
+	want, _ := quaternion.FromAxisAngle(vector.NewVector(0, 1, 0), math.Pi/3)
+	assert.True(t, vector.Equals(c.Translation, vector.NewVector(1, -2, 5)))
+	assert.True(t, vector.Equals(c.Scale, vector.NewVector(2, 3, 4)))
+	assert.True(t, quaternion.Equals(c.Rotation, want), "got %v", c.Rotation)
+	assert.Equal(t, Shear{}, roundShear(c.Shear))
+}
+
+func roundShear(s Shear) Shear {
+	round := func(v float64) float64 { return math.Round(v*1e9) / 1e9 }
+	return Shear{XY: round(s.XY), XZ: round(s.XZ), YZ: round(s.YZ)}
+}
+
+func TestDecomposeNegativeScale(t *testing.T) {
+	c, err := Decompose(matrix.Scaling(-1, 2, 1))
+	assert.Nil(t, err)
+	assert.True(t, vector.Equals(c.Scale, vector.NewVector(-1, 2, 1)))
+	assert.True(t, quaternion.Equals(c.Rotation, quaternion.Identity()))
+
+	// Mirroring along another axis is moved to X and compensated by rotation
+	m := chain(t, matrix.Scaling(1, -3, 1), matrix.RotationZ(0.5))
+	c, err = Decompose(m)
+	assert.Nil(t, err)
+	assert.True(t, c.Scale.X < 0)
+	assert.True(t, matrix.IsEqual(Recompose(c), m))
+}
+
+func TestDecomposeRoundTrip(t *testing.T) {
+	axis := vector.NewVector(1, 2, -3)
+	q, _ := quaternion.FromAxisAngle(axis, 2.5)
+
+	tests := map[string]*matrix.Matrix{
+		"identity":             matrix.Identity(4),
+		"translation":          matrix.Translation(4, 5, 6),
+		"rotation":             quaternion.ToMatrix(q),
+		"scale and rotate":     chain(t, matrix.Scaling(0.5, 2, 7), matrix.RotationX(1), matrix.RotationZ(-2)),
+		"sheared":              chain(t, matrix.Shearing(0.5, 0, 0.25, 0, -1, 0.3), matrix.RotationY(0.7), matrix.Translation(1, 1, 1)),
+		"mirrored":             chain(t, matrix.Scaling(2, 2, -2), quaternion.ToMatrix(q), matrix.Translation(0, 3, 0)),
+		"rotated by almost pi": chain(t, matrix.Scaling(1, 2, 3), matrix.RotationX(math.Pi-1e-3)),
+	}
+
+	for name, m := range tests {
+		c, err := Decompose(m)
+		assert.Nil(t, err, name)
+		got := Recompose(c)
+		if !matrix.IsEqual(got, m) {
+			t.Fatalf("%s: expected: %v, got %v", name, m, got)
+		}
+		assert.InDelta(t, 1, quaternion.Magnitude(c.Rotation), 1e-9, name)
+	}
+}
+
+func TestDecomposeTinyScale(t *testing.T) {
+	// The default tolerance would find any two matrices this small equal
+	tol := util.Tolerance{Abs: 1e-18, Rel: 1e-9}
+	tests := map[string]*matrix.Matrix{
+		"uniform":           matrix.Scaling(1e-6, 1e-6, 1e-6),
+		"uniform and moved": chain(t, matrix.Scaling(1e-6, 1e-6, 1e-6), matrix.RotationY(0.3), matrix.Translation(1, 2, 3)),
+		"mixed":             matrix.Scaling(1e-6, 1, 100),
+	}
+
+	for name, m := range tests {
+		c, err := Decompose(m)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		got := Recompose(c)
+		if !matrix.IsEqual(got, m, tol) {
+			t.Fatalf("%s: expected: %v, got %v", name, m, got)
+		}
+	}
+	c, err := Decompose(matrix.Scaling(1e-6, 1e-6, 1e-6))
+	assert.Nil(t, err)
+	assert.True(t, vector.Equals(c.Scale, vector.NewVector(1e-6, 1e-6, 1e-6), tol), "got %v", c.Scale)
+}
+
+func TestDecomposeErrors(t *testing.T) {
+	tests := map[string]*matrix.Matrix{
+		"not 4x4":    matrix.Identity(3),
+		"singular":   matrix.Scaling(1, 0, 1),
+		"flat":       chain(t, matrix.Shearing(1, 0, 1, 0, 0, 0)),
+		"projective": matrix.NewMatrix([][]float64{{1, 0, 0, 0}, {0, 1, 0, 0}, {0, 0, 1, 0}, {0, 0, 1, 0}}),
+	}
+	for name, m := range tests {
+		_, err := Decompose(m)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	a, err := Decompose(matrix.Identity(4))
+	assert.Nil(t, err)
+	b, err := Decompose(chain(t, matrix.Scaling(3, 3, 3), matrix.RotationY(math.Pi/2), matrix.Translation(2, 0, 0)))
+	assert.Nil(t, err)
+
+	half := Interpolate(a, b, 0.5)
+	want := chain(t, matrix.Scaling(2, 2, 2), matrix.RotationY(math.Pi/4), matrix.Translation(1, 0, 0))
+	assert.True(t, matrix.IsEqual(Recompose(half), want))
+	assert.True(t, matrix.IsEqual(Recompose(Interpolate(a, b, 1)), Recompose(b)))
+}
+
+func TestEulerAngles(t *testing.T) {
+	tests := map[string][3]float64{
+		"zero":         {0, 0, 0},
+		"single axis":  {0.5, 0, 0},
+		"all axes":     {0.3, -0.7, 2.1},
+		"negative":     {-2.5, 1.2, -0.4},
+		"gimbal lock":  {0.4, math.Pi / 2, 0},
+		"gimbal lock2": {-1.1, -math.Pi / 2, 0},
+	}
+
+	for name, angles := range tests {
+		m := chain(t, matrix.RotationX(angles[0]), matrix.RotationY(angles[1]), matrix.RotationZ(angles[2]))
+		q, err := quaternion.FromMatrix(m)
+		assert.Nil(t, err, name)
+		x, y, z := EulerAngles(q)
+		got := chain(t, matrix.RotationX(x), matrix.RotationY(y), matrix.RotationZ(z))
+		if !matrix.IsEqual(got, m) {
+			t.Fatalf("%s: expected angles %v, got %v %v %v", name, angles, x, y, z)
+		}
+		assert.InDelta(t, angles[1], y, 1e-6, name)
+	}
+}
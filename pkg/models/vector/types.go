@@ -0,0 +1,165 @@
+package vector
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+)
+
+// Point, Vec3 and Normal are 3D alternatives to Vector that keep the kind of the
+// value in its type instead of in W, so that only meaningful operations compile,
+// e.g. two points can be subtracted, but not added together.
+
+// Point is a location in space
+type Point struct {
+	X, Y, Z float64
+}
+
+// Vec3 is a direction with a length, e.g. the difference of two points
+type Vec3 struct {
+	X, Y, Z float64
+}
+
+// Normal is a direction perpendicular to a surface. It's kept apart from Vec3
+// because normals have to be transformed by the inverse transpose of a matrix.
+type Normal struct {
+	X, Y, Z float64
+}
+
+// Add returns p moved by v
+func (p Point) Add(v Vec3) Point {
+	return Point{p.X + v.X, p.Y + v.Y, p.Z + v.Z}
+}
+
+// Subtract returns the vector going from o to p
+func (p Point) Subtract(o Point) Vec3 {
+	return Vec3{p.X - o.X, p.Y - o.Y, p.Z - o.Z}
+}
+
+// Equals compares p and o for equality using the optional tolerance
+func (p Point) Equals(o Point, tol ...util.Tolerance) bool {
+	return equals3(p.X, p.Y, p.Z, o.X, o.Y, o.Z, util.ToleranceOrDefault(tol...))
+}
+
+// Vector returns p as a 4D point Vector
+func (p Point) Vector() Vector {
+	return Vector{p.X, p.Y, p.Z, 1}
+}
+
+// Add returns the sum of v and o
+func (v Vec3) Add(o Vec3) Vec3 {
+	return Vec3{v.X + o.X, v.Y + o.Y, v.Z + o.Z}
+}
+
+// Subtract returns v minus o
+func (v Vec3) Subtract(o Vec3) Vec3 {
+	return Vec3{v.X - o.X, v.Y - o.Y, v.Z - o.Z}
+}
+
+// Negate returns v pointing in the opposite direction
+func (v Vec3) Negate() Vec3 {
+	return Vec3{-v.X, -v.Y, -v.Z}
+}
+
+// Multiply returns v scaled by s
+func (v Vec3) Multiply(s float64) Vec3 {
+	return Vec3{v.X * s, v.Y * s, v.Z * s}
+}
+
+// Magnitude returns the length of v
+func (v Vec3) Magnitude() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z)
+}
+
+// Normalize returns unit vector in the direction of v or error if v is zero
+func (v Vec3) Normalize() (Vec3, error) {
+	x, y, z, err := normalize3(v.X, v.Y, v.Z)
+	return Vec3{x, y, z}, err
+}
+
+// Dot returns the dot product of v and o
+func (v Vec3) Dot(o Vec3) float64 {
+	return v.X*o.X + v.Y*o.Y + v.Z*o.Z
+}
+
+// Cross returns the cross product of v and o
+func (v Vec3) Cross(o Vec3) Vec3 {
+	return Vec3{v.Y*o.Z - v.Z*o.Y, v.Z*o.X - v.X*o.Z, v.X*o.Y - v.Y*o.X}
+}
+
+// Equals compares v and o for equality using the optional tolerance
+func (v Vec3) Equals(o Vec3, tol ...util.Tolerance) bool {
+	return equals3(v.X, v.Y, v.Z, o.X, o.Y, o.Z, util.ToleranceOrDefault(tol...))
+}
+
+// Vector returns v as a 4D Vector with W = 0
+func (v Vec3) Vector() Vector {
+	return Vector{v.X, v.Y, v.Z, 0}
+}
+
+// Normal returns unit normal in the direction of v or error if v is zero
+func (v Vec3) Normal() (Normal, error) {
+	x, y, z, err := normalize3(v.X, v.Y, v.Z)
+	return Normal{x, y, z}, err
+}
+
+// Negate returns n pointing to the other side of the surface
+func (n Normal) Negate() Normal {
+	return Normal{-n.X, -n.Y, -n.Z}
+}
+
+// Normalize returns n scaled to unit length or error if n is zero
+func (n Normal) Normalize() (Normal, error) {
+	x, y, z, err := normalize3(n.X, n.Y, n.Z)
+	return Normal{x, y, z}, err
+}
+
+// Dot returns the dot product of n and v, e.g. the cosine of the angle between
+// the surface normal and the direction to a light
+func (n Normal) Dot(v Vec3) float64 {
+	return n.X*v.X + n.Y*v.Y + n.Z*v.Z
+}
+
+// Vec3 returns n as a plain direction
+func (n Normal) Vec3() Vec3 {
+	return Vec3{n.X, n.Y, n.Z}
+}
+
+// Equals compares n and o for equality using the optional tolerance
+func (n Normal) Equals(o Normal, tol ...util.Tolerance) bool {
+	return equals3(n.X, n.Y, n.Z, o.X, o.Y, o.Z, util.ToleranceOrDefault(tol...))
+}
+
+// Vector returns n as a 4D Vector with W = 0
+func (n Normal) Vector() Vector {
+	return Vector{n.X, n.Y, n.Z, 0}
+}
+
+// Point converts a 4D point to Point or returns error if v is not a point
+func (v Vector) Point() (Point, error) {
+	if !IsPoint(&v) {
+		return Point{}, errors.New("vector is not a point")
+	}
+	return Point{v.X, v.Y, v.Z}, nil
+}
+
+// Vec3 converts a 4D vector to Vec3 or returns error if v is a point
+func (v Vector) Vec3() (Vec3, error) {
+	if !IsVector(&v) {
+		return Vec3{}, errors.New("vector is a point, not a direction")
+	}
+	return Vec3{v.X, v.Y, v.Z}, nil
+}
+
+func equals3(x1, y1, z1, x2, y2, z2 float64, t util.Tolerance) bool {
+	return t.Equals(x1, x2) && t.Equals(y1, y2) && t.Equals(z1, z2)
+}
+
+func normalize3(x, y, z float64) (float64, float64, float64, error) {
+	m := math.Sqrt(x*x + y*y + z*z)
+	if util.FloatEquals(m, 0) {
+		return 0, 0, 0, errNormalizeZero
+	}
+	return x / m, y / m, z / m, nil
+}
@@ -0,0 +1,82 @@
+package vector
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointOperations(t *testing.T) {
+	p1 := Point{3, 2, 1}
+	p2 := Point{5, 6, 7}
+
+	assert.True(t, p1.Subtract(p2).Equals(Vec3{-2, -4, -6}))
+	assert.True(t, p1.Add(Vec3{1, 1, 1}).Equals(Point{4, 3, 2}))
+	assert.True(t, p1.Add(p2.Subtract(p1)).Equals(p2))
+	assert.Equal(t, Vector{3, 2, 1, 1}, p1.Vector())
+}
+
+func TestEqualsWithTolerance(t *testing.T) {
+	loose := util.Tolerance{Abs: 0.01}
+	assert.False(t, Point{1, 2, 3}.Equals(Point{1, 2, 3.001}))
+	assert.True(t, Point{1, 2, 3}.Equals(Point{1, 2, 3.001}, loose))
+	assert.True(t, Vec3{1e6, 0, 0}.Equals(Vec3{1e6 + 0.5, 0, 0}, util.Tolerance{Rel: 1e-6}))
+	assert.False(t, Vec3{1, 0, 0}.Equals(Vec3{1.001, 0, 0}))
+	assert.True(t, Normal{0, 1, 0}.Equals(Normal{0, 0.999, 0}, loose))
+	assert.False(t, Normal{0, 1, 0}.Equals(Normal{0, 1 + 1e-9, 0}, util.Tolerance{}))
+}
+
+func TestVec3Operations(t *testing.T) {
+	v1 := Vec3{1, 2, 3}
+	v2 := Vec3{2, 3, 4}
+
+	assert.True(t, v1.Add(v2).Equals(Vec3{3, 5, 7}))
+	assert.True(t, v1.Subtract(v2).Equals(Vec3{-1, -1, -1}))
+	assert.True(t, v1.Negate().Equals(Vec3{-1, -2, -3}))
+	assert.True(t, v1.Multiply(2).Equals(Vec3{2, 4, 6}))
+	assert.Equal(t, 20.0, v1.Dot(v2))
+	assert.True(t, v1.Cross(v2).Equals(Vec3{-1, 2, -1}))
+	assert.Equal(t, math.Sqrt(14), v1.Magnitude())
+	assert.Equal(t, Vector{1, 2, 3, 0}, v1.Vector())
+
+	n, err := Vec3{0, 3, 4}.Normalize()
+	assert.Nil(t, err)
+	assert.True(t, n.Equals(Vec3{0, 0.6, 0.8}))
+	_, err = Vec3{}.Normalize()
+	assert.NotNil(t, err)
+}
+
+func TestNormalOperations(t *testing.T) {
+	n, err := Vec3{0, 0, 2}.Normal()
+	assert.Nil(t, err)
+	assert.True(t, n.Equals(Normal{0, 0, 1}))
+	assert.True(t, n.Negate().Equals(Normal{0, 0, -1}))
+	assert.Equal(t, 0.5, n.Dot(Vec3{1, 0, 0.5}))
+	assert.Equal(t, Vec3{0, 0, 1}, n.Vec3())
+	assert.Equal(t, Vector{0, 0, 1, 0}, n.Vector())
+
+	n, err = Normal{3, 0, 4}.Normalize()
+	assert.Nil(t, err)
+	assert.True(t, n.Equals(Normal{0.6, 0, 0.8}))
+	_, err = Vec3{}.Normal()
+	assert.NotNil(t, err)
+}
+
+func TestConversionsFromVector(t *testing.T) {
+	p, err := NewPoint(1, 2, 3).Point()
+	assert.Nil(t, err)
+	assert.Equal(t, Point{1, 2, 3}, p)
+	_, err = NewVector(1, 2, 3).Point()
+	assert.NotNil(t, err)
+
+	v, err := NewVector(1, 2, 3).Vec3()
+	assert.Nil(t, err)
+	assert.Equal(t, Vec3{1, 2, 3}, v)
+	_, err = NewPoint(1, 2, 3).Vec3()
+	assert.NotNil(t, err)
+	// Adding two points gives W = 2, which is neither
+	_, err = Add(NewPoint(1, 2, 3), NewPoint(1, 2, 3)).Point()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,164 @@
+package vector
+
+import (
+	"errors"
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+)
+
+// Methods below work on Vector values instead of pointers, so unlike the package
+// functions they don't allocate and are meant for hot loops like intersecting
+// rays. Package functions are implemented on top of them.
+
+var (
+	errDivideByZero  = errors.New("attempt to divide vector by 0")
+	errNormalizeZero = errors.New("attempt to normalize zero vector")
+	errZeroAngle     = errors.New("angle with zero vector is undefined")
+	errProjectZero   = errors.New("attempt to project onto zero vector")
+)
+
+// Add returns the sum of v and o
+func (v Vector) Add(o Vector) Vector {
+	return Vector{v.X + o.X, v.Y + o.Y, v.Z + o.Z, v.W + o.W}
+}
+
+// Subtract returns v minus o
+func (v Vector) Subtract(o Vector) Vector {
+	return Vector{v.X - o.X, v.Y - o.Y, v.Z - o.Z, v.W - o.W}
+}
+
+// Negate returns v with all of the signs inverted
+func (v Vector) Negate() Vector {
+	return Vector{-v.X, -v.Y, -v.Z, -v.W}
+}
+
+// Multiply returns v scaled by s
+func (v Vector) Multiply(s float64) Vector {
+	return Vector{v.X * s, v.Y * s, v.Z * s, v.W * s}
+}
+
+// Divide returns v divided by s or error if s is 0
+func (v Vector) Divide(s float64) (Vector, error) {
+	if util.FloatEquals(s, 0) {
+		return Vector{}, errDivideByZero
+	}
+	return Vector{v.X / s, v.Y / s, v.Z / s, v.W / s}, nil
+}
+
+// Magnitude returns the length of v
+func (v Vector) Magnitude() float64 {
+	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z + v.W*v.W)
+}
+
+// Normalize returns unit vector in the direction of v or error if v is zero
+func (v Vector) Normalize() (Vector, error) {
+	m := v.Magnitude()
+	if util.FloatEquals(m, 0) {
+		return Vector{}, errNormalizeZero
+	}
+	return Vector{v.X / m, v.Y / m, v.Z / m, v.W / m}, nil
+}
+
+// Dot returns the dot product of v and o
+func (v Vector) Dot(o Vector) float64 {
+	return v.X*o.X + v.Y*o.Y + v.Z*o.Z + v.W*o.W
+}
+
+// Cross returns the cross product of v and o, which is always a vector
+func (v Vector) Cross(o Vector) Vector {
+	return Vector{v.Y*o.Z - v.Z*o.Y, v.Z*o.X - v.X*o.Z, v.X*o.Y - v.Y*o.X, 0}
+}
+
+// Equals compares v and o for equality using the optional tolerance, or
+// util.DefaultTolerance if none is given
+func (v Vector) Equals(o Vector, tol ...util.Tolerance) bool {
+	t := util.ToleranceOrDefault(tol...)
+	return t.Equals(v.X, o.X) && t.Equals(v.Y, o.Y) &&
+		t.Equals(v.Z, o.Z) && t.Equals(v.W, o.W)
+}
+
+// Reflect returns v reflected around normal, which has to be normalized
+func (v Vector) Reflect(normal Vector) Vector {
+	return v.Subtract(normal.Multiply(2 * v.Dot(normal)))
+}
+
+// Refract returns the direction of v after passing through a surface with
+// normal, where eta is the ratio of refractive indices of the medium v comes
+// from and the one it enters. Both vectors have to be normalized and normal has
+// to point against v. It returns false instead if there is total internal
+// reflection.
+func (v Vector) Refract(normal Vector, eta float64) (Vector, bool) {
+	cosI := -v.Dot(normal)
+	sin2T := eta * eta * (1 - cosI*cosI)
+	if sin2T > 1 {
+		return Vector{}, false
+	}
+	cosT := math.Sqrt(1 - sin2T)
+	return v.Multiply(eta).Add(normal.Multiply(eta*cosI - cosT)), true
+}
+
+// Lerp interpolates linearly between v for t = 0 and o for t = 1
+func (v Vector) Lerp(o Vector, t float64) Vector {
+	return v.Add(o.Subtract(v).Multiply(t))
+}
+
+// Min returns the smaller of every component of v and o
+func (v Vector) Min(o Vector) Vector {
+	return Vector{math.Min(v.X, o.X), math.Min(v.Y, o.Y), math.Min(v.Z, o.Z), math.Min(v.W, o.W)}
+}
+
+// Max returns the larger of every component of v and o
+func (v Vector) Max(o Vector) Vector {
+	return Vector{math.Max(v.X, o.X), math.Max(v.Y, o.Y), math.Max(v.Z, o.Z), math.Max(v.W, o.W)}
+}
+
+// Abs returns v with absolute values of every component
+func (v Vector) Abs() Vector {
+	return Vector{math.Abs(v.X), math.Abs(v.Y), math.Abs(v.Z), math.Abs(v.W)}
+}
+
+// AngleBetween returns the angle between v and o in radians or error if one of
+// them is zero
+func (v Vector) AngleBetween(o Vector) (float64, error) {
+	m := v.Magnitude() * o.Magnitude()
+	if util.FloatEquals(m, 0) {
+		return 0, errZeroAngle
+	}
+	// Rounding errors can push the cosine of parallel vectors slightly above 1
+	return math.Acos(math.Max(-1, math.Min(1, v.Dot(o)/m))), nil
+}
+
+// Project returns the part of v parallel to onto or error if onto is zero
+func (v Vector) Project(onto Vector) (Vector, error) {
+	d := onto.Dot(onto)
+	if util.FloatEquals(d, 0) {
+		return Vector{}, errProjectZero
+	}
+	return onto.Multiply(v.Dot(onto) / d), nil
+}
+
+// Reject returns the part of v perpendicular to onto or error if onto is zero
+func (v Vector) Reject(onto Vector) (Vector, error) {
+	p, err := v.Project(onto)
+	if err != nil {
+		return Vector{}, err
+	}
+	return v.Subtract(p), nil
+}
+
+// OrthonormalBasis returns two unit vectors that together with normalized v form
+// an orthonormal basis, or error if v is zero. It uses the branchless method of
+// Duff et al., which is continuous everywhere except for v pointing along -Z.
+func (v Vector) OrthonormalBasis() (Vector, Vector, error) {
+	n, err := Vector{v.X, v.Y, v.Z, 0}.Normalize()
+	if err != nil {
+		return Vector{}, Vector{}, err
+	}
+	sign := math.Copysign(1, n.Z)
+	a := -1 / (sign + n.Z)
+	b := n.X * n.Y * a
+	tangent := Vector{1 + sign*n.X*n.X*a, sign * b, -sign * n.X, 0}
+	bitangent := Vector{b, sign + n.Y*n.Y*a, -n.Y, 0}
+	return tangent, bitangent, nil
+}
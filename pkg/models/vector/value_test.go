@@ -0,0 +1,131 @@
+package vector
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueMethods(t *testing.T) {
+	v1 := Vector{1, -2, 3, -4}
+	v2 := Vector{2, 3, 4, 1}
+
+	assert.True(t, v1.Add(v2).Equals(Vector{3, 1, 7, -3}))
+	assert.True(t, v1.Subtract(v2).Equals(Vector{-1, -5, -1, -5}))
+	assert.True(t, v1.Negate().Equals(Vector{-1, 2, -3, 4}))
+	assert.True(t, v1.Multiply(0.5).Equals(Vector{0.5, -1, 1.5, -2}))
+	assert.Equal(t, 4.0, v1.Dot(v2))
+	assert.Equal(t, math.Sqrt(30), v1.Magnitude())
+	assert.True(t, Vector{1, 2, 3, 0}.Cross(Vector{2, 3, 4, 0}).Equals(Vector{-1, 2, -1, 0}))
+
+	d, err := v1.Divide(2)
+	assert.Nil(t, err)
+	assert.True(t, d.Equals(Vector{0.5, -1, 1.5, -2}))
+	_, err = v1.Divide(0)
+	assert.NotNil(t, err)
+
+	n, err := Vector{4, 0, 0, 0}.Normalize()
+	assert.Nil(t, err)
+	assert.True(t, n.Equals(Vector{1, 0, 0, 0}))
+	_, err = Vector{}.Normalize()
+	assert.NotNil(t, err)
+
+	// Methods never modify their receiver
+	assert.Equal(t, Vector{1, -2, 3, -4}, v1)
+}
+
+// hit is what a tracer keeps about an intersection for later shading
+type hit struct {
+	point, normal Vector
+}
+
+type hitPointers struct {
+	point, normal *Vector
+}
+
+var (
+	lastHit        hit
+	lastHitPointer hitPointers
+)
+
+// shadeValues computes diffuse lighting of a unit sphere at the origin for a ray
+// from origin going in direction, the way a tracer would, and records the hit
+func shadeValues(origin, direction, light Vector) float64 {
+	toRay := origin.Subtract(Vector{0, 0, 0, 1})
+	a := direction.Dot(direction)
+	b := 2 * direction.Dot(toRay)
+	c := toRay.Dot(toRay) - 1
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return 0
+	}
+	t := (-b - math.Sqrt(discriminant)) / (2 * a)
+	point := origin.Add(direction.Multiply(t))
+	normal, err := point.Subtract(Vector{0, 0, 0, 1}).Normalize()
+	if err != nil {
+		return 0
+	}
+	lastHit = hit{point, normal}
+	toLight, err := light.Subtract(point).Normalize()
+	if err != nil {
+		return 0
+	}
+	return math.Max(normal.Dot(toLight), 0)
+}
+
+// shadePointers is shadeValues written with the package functions
+func shadePointers(origin, direction, light *Vector) float64 {
+	toRay := Subtract(origin, NewPoint(0, 0, 0))
+	a := Dot(direction, direction)
+	b := 2 * Dot(direction, toRay)
+	c := Dot(toRay, toRay) - 1
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return 0
+	}
+	t := (-b - math.Sqrt(discriminant)) / (2 * a)
+	point := Add(origin, Multiply(direction, t))
+	normal, err := Normalize(Subtract(point, NewPoint(0, 0, 0)))
+	if err != nil {
+		return 0
+	}
+	lastHitPointer = hitPointers{point, normal}
+	toLight, err := Normalize(Subtract(light, point))
+	if err != nil {
+		return 0
+	}
+	return math.Max(Dot(normal, toLight), 0)
+}
+
+func TestShadingWithValuesDoesNotAllocate(t *testing.T) {
+	origin, direction, light := Vector{0, 0, -5, 1}, Vector{0.1, 0.05, 1, 0}, Vector{-10, 10, -10, 1}
+	want := shadePointers(&origin, &direction, &light)
+	assert.True(t, want > 0)
+	assert.Equal(t, want, shadeValues(origin, direction, light))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		shadeValues(origin, direction, light)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+var shadeResult float64
+
+func BenchmarkShadingValues(b *testing.B) {
+	b.ReportAllocs()
+	origin, light := Vector{0, 0, -5, 1}, Vector{-10, 10, -10, 1}
+	for i := 0; i < b.N; i++ {
+		direction := Vector{float64(i%100)/1000 - 0.05, 0.1, 1, 0}
+		shadeResult += shadeValues(origin, direction, light)
+	}
+}
+
+func BenchmarkShadingPointers(b *testing.B) {
+	b.ReportAllocs()
+	origin, light := NewPoint(0, 0, -5), NewPoint(-10, 10, -10)
+	for i := 0; i < b.N; i++ {
+		direction := NewVector(float64(i%100)/1000-0.05, 0.1, 1)
+		shadeResult += shadePointers(origin, direction, light)
+	}
+}
@@ -2,7 +2,6 @@ package vector
 
 import (
 	"errors"
-	"math"
 
 	"github.com/alex-petrov-vt/raytracer/pkg/util"
 )
@@ -71,91 +70,141 @@ func IsVector(v *Vector) bool {
 	return util.FloatEquals(v.W, 0.0)
 }
 
-// Equals compares two Vectors for equality
-func Equals(v1, v2 *Vector) bool {
-	return util.FloatEquals(v1.X, v2.X) && util.FloatEquals(v1.Y, v2.Y) &&
-		util.FloatEquals(v1.Z, v2.Z) && util.FloatEquals(v1.W, v2.W)
+// Equals compares two Vectors for equality using the optional tolerance
+func Equals(v1, v2 *Vector, tol ...util.Tolerance) bool {
+	return v1.Equals(*v2, tol...)
 }
 
 // Add adds two Vectors together and returns the resulting Vector
 func Add(t1, t2 *Vector) *Vector {
-	return &Vector{
-		t1.X + t2.X,
-		t1.Y + t2.Y,
-		t1.Z + t2.Z,
-		t1.W + t2.W,
-	}
+	result := t1.Add(*t2)
+	return &result
 }
 
 // Subtract subtracts second Vector from the first Vector and returns the resulting
 // Vector (or Point)
 func Subtract(v1, v2 *Vector) *Vector {
-	return &Vector{
-		v1.X - v2.X,
-		v1.Y - v2.Y,
-		v1.Z - v2.Z,
-		v1.W - v2.W,
-	}
+	result := v1.Subtract(*v2)
+	return &result
 }
 
 // Negate inverts all of the signs of the Vector coordinates
 func Negate(v *Vector) *Vector {
-	return &Vector{
-		-v.X,
-		-v.Y,
-		-v.Z,
-		-v.W,
-	}
+	result := v.Negate()
+	return &result
 }
 
 // Multiply multiples Vector by a scalar
 func Multiply(v *Vector, s float64) *Vector {
-	return &Vector{
-		v.X * s,
-		v.Y * s,
-		v.Z * s,
-		v.W * s,
-	}
+	result := v.Multiply(s)
+	return &result
 }
 
 // Divide divides Vector by a scalar
 func Divide(v *Vector, s float64) (*Vector, error) {
-	if util.FloatEquals(s, 0) {
-		return nil, errors.New("attempt to divide vector by 0")
+	result, err := v.Divide(s)
+	if err != nil {
+		return nil, err
 	}
-	return &Vector{
-		v.X / s,
-		v.Y / s,
-		v.Z / s,
-		v.W / s,
-	}, nil
+	return &result, nil
 }
 
 // Magnitude returns the magnitude of the vector (sqrt of squares of its terms)
 func Magnitude(v *Vector) float64 {
-	return math.Sqrt(v.X*v.X + v.Y*v.Y + v.Z*v.Z + v.W*v.W)
+	return v.Magnitude()
 }
 
 // Normalize scales vector t to be unit vector in its direction
 func Normalize(v *Vector) (*Vector, error) {
-	m := Magnitude(v)
-	if util.FloatEquals(m, 0) {
-		return nil, errors.New("attempt to normalize zero vector")
+	result, err := v.Normalize()
+	if err != nil {
+		return nil, err
 	}
-	return &Vector{
-		v.X / m,
-		v.Y / m,
-		v.Z / m,
-		v.W / m,
-	}, nil
+	return &result, nil
 }
 
 // Dot returns a dot product for vectors t1 and t2
 func Dot(v1, v2 *Vector) float64 {
-	return v1.X*v2.X + v1.Y*v2.Y + v1.Z*v2.Z + v1.W*v2.W
+	return v1.Dot(*v2)
 }
 
 // Cross returns a cross product vector for vectors t1 and t2
 func Cross(v1, v2 *Vector) *Vector {
-	return NewVector(v1.Y*v2.Z-v1.Z*v2.Y, v1.Z*v2.X-v1.X*v2.Z, v1.X*v2.Y-v1.Y*v2.X)
+	result := v1.Cross(*v2)
+	return &result
+}
+
+// Reflect returns vector in reflected around normal, which has to be normalized
+func Reflect(in, normal *Vector) *Vector {
+	result := in.Reflect(*normal)
+	return &result
+}
+
+// Refract returns the direction of in after passing through a surface with
+// normal, where eta is the ratio of refractive indices of the medium in comes
+// from and the one it enters. It returns false if there is total internal
+// reflection, see Vector.Refract for details.
+func Refract(in, normal *Vector, eta float64) (*Vector, bool) {
+	result, ok := in.Refract(*normal, eta)
+	if !ok {
+		return nil, false
+	}
+	return &result, true
+}
+
+// Lerp interpolates linearly between v1 for t = 0 and v2 for t = 1
+func Lerp(v1, v2 *Vector, t float64) *Vector {
+	result := v1.Lerp(*v2, t)
+	return &result
+}
+
+// Min returns a Vector of the smaller components of v1 and v2
+func Min(v1, v2 *Vector) *Vector {
+	result := v1.Min(*v2)
+	return &result
+}
+
+// Max returns a Vector of the larger components of v1 and v2
+func Max(v1, v2 *Vector) *Vector {
+	result := v1.Max(*v2)
+	return &result
+}
+
+// Abs returns a Vector of absolute values of components of v
+func Abs(v *Vector) *Vector {
+	result := v.Abs()
+	return &result
+}
+
+// AngleBetween returns the angle between v1 and v2 in radians
+func AngleBetween(v1, v2 *Vector) (float64, error) {
+	return v1.AngleBetween(*v2)
+}
+
+// Project returns the part of v parallel to onto
+func Project(v, onto *Vector) (*Vector, error) {
+	result, err := v.Project(*onto)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Reject returns the part of v perpendicular to onto
+func Reject(v, onto *Vector) (*Vector, error) {
+	result, err := v.Reject(*onto)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// OrthonormalBasis returns two unit vectors that are perpendicular to normal
+// and to each other
+func OrthonormalBasis(normal *Vector) (*Vector, *Vector, error) {
+	t, b, err := normal.OrthonormalBasis()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &t, &b, nil
 }
@@ -54,6 +54,12 @@ func TestVectorEquals(v *testing.T) {
 	v3 := NewVector(1.1, 2, -3)
 	assert.True(v, Equals(v1, v2))
 	assert.False(v, Equals(v1, v3))
+
+	far := NewPoint(1e12, -1e12, 3)
+	nudged := NewPoint(1e12+1e-3, -1e12, 3)
+	assert.True(v, Equals(far, nudged))
+	assert.False(v, Equals(far, nudged, util.Tolerance{Abs: 1e-5}))
+	assert.True(v, v1.Equals(*NewVector(1.05, 2, 3), util.Tolerance{Rel: 0.1}))
 }
 
 func TestAdd(t *testing.T) {
@@ -175,3 +181,121 @@ func TestCrossProduct(t *testing.T) {
 	assert.True(t, Equals(Cross(v1, v2), NewVector(-1, 2, -1)))
 	assert.True(t, Equals(Cross(v2, v1), NewVector(1, -2, 1)))
 }
+
+func TestReflect(t *testing.T) {
+	tests := map[string]struct {
+		in, normal, want *Vector
+	}{
+		"approaching at 45 degrees": {in: NewVector(1, -1, 0), normal: NewVector(0, 1, 0), want: NewVector(1, 1, 0)},
+		"off slanted surface":       {in: NewVector(0, -1, 0), normal: NewVector(math.Sqrt2/2, math.Sqrt2/2, 0), want: NewVector(1, 0, 0)},
+	}
+
+	for name, tc := range tests {
+		got := Reflect(tc.in, tc.normal)
+		if !Equals(got, tc.want) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestRefract(t *testing.T) {
+	normal := NewVector(0, 1, 0)
+	in, _ := Normalize(NewVector(1, -1, 0))
+
+	// Going straight through doesn't bend
+	got, ok := Refract(NewVector(0, -1, 0), normal, 1/1.5)
+	assert.True(t, ok)
+	assert.True(t, Equals(got, NewVector(0, -1, 0)))
+
+	// Snell's law: sin of the refracted angle is eta times sin of the incident one
+	got, ok = Refract(in, normal, 1/1.5)
+	assert.True(t, ok)
+	assert.InDelta(t, 1.0, Magnitude(got), 1e-9)
+	assert.InDelta(t, math.Sqrt2/2/1.5, got.X, 1e-9)
+	assert.True(t, got.Y < 0)
+
+	// Going from glass to air at 45 degrees is past the critical angle
+	got, ok = Refract(in, normal, 1.5)
+	assert.False(t, ok)
+	assert.Nil(t, got)
+}
+
+func TestLerp(t *testing.T) {
+	v1 := NewPoint(0, 2, 4)
+	v2 := NewPoint(2, 4, 0)
+	assert.True(t, Equals(Lerp(v1, v2, 0), v1))
+	assert.True(t, Equals(Lerp(v1, v2, 1), v2))
+	assert.True(t, Equals(Lerp(v1, v2, 0.25), NewPoint(0.5, 2.5, 3)))
+}
+
+func TestComponentWise(t *testing.T) {
+	v1 := New4DVector(1, -5, 3, 0)
+	v2 := New4DVector(-2, 4, 3, 1)
+	assert.True(t, Equals(Min(v1, v2), New4DVector(-2, -5, 3, 0)))
+	assert.True(t, Equals(Max(v1, v2), New4DVector(1, 4, 3, 1)))
+	assert.True(t, Equals(Abs(v1), New4DVector(1, 5, 3, 0)))
+}
+
+func TestAngleBetween(t *testing.T) {
+	tests := map[string]struct {
+		v1, v2 *Vector
+		want   float64
+	}{
+		"perpendicular": {v1: NewVector(1, 0, 0), v2: NewVector(0, 3, 0), want: math.Pi / 2},
+		"parallel":      {v1: NewVector(1, 2, 3), v2: NewVector(2, 4, 6), want: 0},
+		"opposite":      {v1: NewVector(1, 1, 0), v2: NewVector(-1, -1, 0), want: math.Pi},
+		"45 degrees":    {v1: NewVector(1, 0, 0), v2: NewVector(1, 1, 0), want: math.Pi / 4},
+	}
+
+	for name, tc := range tests {
+		got, err := AngleBetween(tc.v1, tc.v2)
+		assert.Nil(t, err, name)
+		if !util.FloatEquals(tc.want, got) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+	_, err := AngleBetween(NewVector(1, 0, 0), NewVector(0, 0, 0))
+	assert.NotNil(t, err)
+}
+
+func TestProjectAndReject(t *testing.T) {
+	v := NewVector(3, 4, 0)
+	onto := NewVector(2, 0, 0)
+
+	p, err := Project(v, onto)
+	assert.Nil(t, err)
+	assert.True(t, Equals(p, NewVector(3, 0, 0)))
+	r, err := Reject(v, onto)
+	assert.Nil(t, err)
+	assert.True(t, Equals(r, NewVector(0, 4, 0)))
+	assert.True(t, Equals(Add(p, r), v))
+
+	_, err = Project(v, NewVector(0, 0, 0))
+	assert.NotNil(t, err)
+	_, err = Reject(v, NewVector(0, 0, 0))
+	assert.NotNil(t, err)
+}
+
+func TestOrthonormalBasis(t *testing.T) {
+	normals := map[string]*Vector{
+		"up":            NewVector(0, 1, 0),
+		"along z":       NewVector(0, 0, 5),
+		"along minus z": NewVector(0, 0, -1),
+		"arbitrary":     NewVector(1, -2, 3),
+	}
+
+	for name, normal := range normals {
+		tangent, bitangent, err := OrthonormalBasis(normal)
+		assert.Nil(t, err, name)
+		n, _ := Normalize(normal)
+		for _, v := range []*Vector{tangent, bitangent} {
+			assert.InDelta(t, 1, Magnitude(v), 1e-9, name)
+			assert.InDelta(t, 0, Dot(v, n), 1e-9, name)
+		}
+		assert.InDelta(t, 0, Dot(tangent, bitangent), 1e-9, name)
+		// Basis is right handed
+		assert.True(t, Equals(Cross(tangent, bitangent), n), name)
+	}
+	_, _, err := OrthonormalBasis(NewVector(0, 0, 0))
+	assert.NotNil(t, err)
+}
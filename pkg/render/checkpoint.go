@@ -0,0 +1,242 @@
+package render
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+const (
+	checkpointMagic   = "RTCP"
+	checkpointVersion = 4
+)
+
+// DefaultCheckpointInterval is the time between two saves used when
+// Checkpoint.Interval is not set
+const DefaultCheckpointInterval = 10 * time.Second
+
+// Checkpoint configures periodic saving of finished tiles to disk, so that an
+// interrupted render can be resumed later without rendering them again. Pixels are
+// stored as raw float64 values, so a resumed render is identical to an
+// uninterrupted one.
+type Checkpoint struct {
+	// Path is the file checkpoint is written to and resumed from
+	Path string
+	// Interval is the minimum time between two saves, DefaultCheckpointInterval if
+	// 0. Every save rewrites all finished tiles and blocks workers from reporting
+	// progress, so very short intervals slow down renders with many tiles. A final
+	// checkpoint is always saved when Render returns.
+	Interval time.Duration
+	// Tag identifies the scene and settings being rendered. Checkpoint saved with
+	// a different tag is rejected instead of mixing pixels of two different images.
+	Tag string
+}
+
+type checkpointHeader struct {
+	Version                   uint16
+	Width, Height, TileSize   uint32
+	TagLength, Layers         uint32
+	TotalTiles, FinishedTiles uint32
+}
+
+func (cp *Checkpoint) interval() time.Duration {
+	if cp.Interval <= 0 {
+		return DefaultCheckpointInterval
+	}
+	return cp.Interval
+}
+
+// save writes pixels of finished tiles of every layer to a temporary file and then
+// moves it to cp.Path, so that the previous checkpoint stays intact if writing
+// fails midway
+func (cp *Checkpoint) save(layers []*canvas.Canvas, passes []string, tiles []Tile, tileSize int, done []bool) error {
+	tmp, err := os.CreateTemp(filepath.Dir(cp.Path), filepath.Base(cp.Path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := cp.write(tmp, layers, passes, tiles, tileSize, done); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), cp.Path)
+}
+
+func (cp *Checkpoint) write(handle io.Writer, layers []*canvas.Canvas, passes []string, tiles []Tile, tileSize int, done []bool) error {
+	w := bufio.NewWriter(handle)
+	finished := 0
+	for _, d := range done {
+		if d {
+			finished++
+		}
+	}
+	header := checkpointHeader{
+		Version:       checkpointVersion,
+		Width:         uint32(layers[0].Width),
+		Height:        uint32(layers[0].Height),
+		TileSize:      uint32(tileSize),
+		TagLength:     uint32(len(cp.Tag)),
+		Layers:        uint32(len(layers)),
+		TotalTiles:    uint32(len(tiles)),
+		FinishedTiles: uint32(finished),
+	}
+	if _, err := w.WriteString(checkpointMagic); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(cp.Tag); err != nil {
+		return err
+	}
+	// Every layer after the first one is a pass, stored as the length of its
+	// name followed by the name
+	for _, name := range passes {
+		if err := binary.Write(w, binary.LittleEndian, uint32(len(name))); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(name); err != nil {
+			return err
+		}
+	}
+
+	for idx, t := range tiles {
+		if !done[idx] {
+			continue
+		}
+		if err := binary.Write(w, binary.LittleEndian, uint32(idx)); err != nil {
+			return err
+		}
+		for _, c := range layers {
+			for y := t.Y; y < t.Y+t.Height; y++ {
+				for x := t.X; x < t.X+t.Width; x++ {
+					col := c.Colors[y][x]
+					alpha, _ := c.GetAlpha(x, y)
+					pix := [4]float64{col.Red, col.Green, col.Blue, alpha}
+					if err := binary.Write(w, binary.LittleEndian, pix); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return w.Flush()
+}
+
+// load copies pixels of tiles stored in the checkpoint file to layers and returns
+// indices of those tiles. Missing checkpoint file means there is nothing to resume.
+func (cp *Checkpoint) load(layers []*canvas.Canvas, passes []string, tiles []Tile, tileSize int) ([]int, error) {
+	handle, err := os.Open(cp.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+
+	done, err := cp.read(handle, layers, passes, tiles, tileSize)
+	if err != nil {
+		return nil, fmt.Errorf("can't resume from checkpoint %s: %w", cp.Path, err)
+	}
+	return done, nil
+}
+
+func (cp *Checkpoint) read(handle io.Reader, layers []*canvas.Canvas, passes []string, tiles []Tile, tileSize int) ([]int, error) {
+	r := bufio.NewReader(handle)
+	magic := make([]byte, len(checkpointMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return nil, err
+	}
+	if string(magic) != checkpointMagic {
+		return nil, errors.New("not a checkpoint file")
+	}
+	var header checkpointHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, err
+	}
+	if header.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d", header.Version)
+	}
+	// Everything the header describes is checked before reading further, so that a
+	// corrupted header can't make it allocate arbitrary amounts of memory
+	if int(header.Width) != layers[0].Width || int(header.Height) != layers[0].Height ||
+		int(header.TileSize) != tileSize || int(header.TotalTiles) != len(tiles) {
+		return nil, errors.New("checkpoint was saved for different image dimensions or tile size")
+	}
+	if int(header.Layers) != len(layers) {
+		return nil, fmt.Errorf("checkpoint was saved with %d layers instead of %d", header.Layers, len(layers))
+	}
+	if int(header.TagLength) != len(cp.Tag) {
+		return nil, errors.New("checkpoint was saved for a different tag")
+	}
+	if header.FinishedTiles > header.TotalTiles {
+		return nil, errors.New("checkpoint has more finished tiles than the image")
+	}
+	tag := make([]byte, header.TagLength)
+	if _, err := io.ReadFull(r, tag); err != nil {
+		return nil, err
+	}
+	if string(tag) != cp.Tag {
+		return nil, fmt.Errorf("checkpoint was saved for tag %q", tag)
+	}
+	for _, want := range passes {
+		var length uint32
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return nil, err
+		}
+		// Names of a different length can't match, so there is no need to read them
+		if int(length) != len(want) {
+			return nil, fmt.Errorf("checkpoint was saved with different passes than %v", passes)
+		}
+		name := make([]byte, length)
+		if _, err := io.ReadFull(r, name); err != nil {
+			return nil, err
+		}
+		if string(name) != want {
+			return nil, fmt.Errorf("checkpoint was saved with pass %s instead of %s", name, want)
+		}
+	}
+
+	var done []int
+	seen := make([]bool, len(tiles))
+	for i := uint32(0); i < header.FinishedTiles; i++ {
+		var idx uint32
+		if err := binary.Read(r, binary.LittleEndian, &idx); err != nil {
+			return nil, err
+		}
+		if int(idx) >= len(tiles) {
+			return nil, fmt.Errorf("tile index %d is outside of the image", idx)
+		}
+		if seen[idx] {
+			return nil, fmt.Errorf("tile %d is stored more than once", idx)
+		}
+		seen[idx] = true
+		t := tiles[idx]
+		for _, c := range layers {
+			for y := t.Y; y < t.Y+t.Height; y++ {
+				for x := t.X; x < t.X+t.Width; x++ {
+					var pix [4]float64
+					if err := binary.Read(r, binary.LittleEndian, &pix); err != nil {
+						return nil, err
+					}
+					c.WritePixelAlpha(x, y, color.NewColor(pix[0], pix[1], pix[2]), pix[3])
+				}
+			}
+		}
+		done = append(done, int(idx))
+	}
+	return done, nil
+}
@@ -0,0 +1,141 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/sampling"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	cp := &Checkpoint{Path: filepath.Join(t.TempDir(), "render.ckpt"), Tag: "gradient"}
+	want, err := Render(context.Background(), 20, 12, gradient, Options{TileSize: 4})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := Options{
+		Workers:    1,
+		TileSize:   4,
+		Checkpoint: cp,
+		Progress: func(p Progress) {
+			if p.TilesDone == 5 {
+				cancel()
+			}
+		},
+	}
+	_, err = Render(ctx, 20, 12, gradient, opts)
+	cancel()
+	assert.True(t, errors.Is(err, ErrInterrupted))
+
+	var calls int64
+	counting := func(x, y float64) *color.Color {
+		atomic.AddInt64(&calls, 1)
+		return gradient(x, y)
+	}
+	var first Progress
+	opts = Options{
+		Workers:    3,
+		TileSize:   4,
+		Checkpoint: cp,
+		Progress: func(p Progress) {
+			if first.TilesDone == 0 {
+				first = p
+			}
+		},
+	}
+	got, err := Render(context.Background(), 20, 12, counting, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, want.Colors, got.Colors)
+	assert.True(t, calls <= 20*12-5*16, "shader called %d times", calls)
+	assert.True(t, first.TilesDone > 5)
+
+	// Everything is in the checkpoint now, so nothing has to be rendered again
+	calls = 0
+	got, err = Render(context.Background(), 20, 12, counting, Options{TileSize: 4, Checkpoint: cp})
+	assert.Nil(t, err)
+	assert.Equal(t, want.Colors, got.Colors)
+	assert.Equal(t, int64(0), calls)
+}
+
+func TestCheckpointKeepsAlpha(t *testing.T) {
+	cp := &Checkpoint{Path: filepath.Join(t.TempDir(), "render.ckpt")}
+	opts := Options{TileSize: 4, Sampler: sampling.Sampler{Samples: 4}, Checkpoint: cp}
+	want, err := Render(context.Background(), 10, 10, disk, opts)
+	assert.Nil(t, err)
+
+	got, err := Render(context.Background(), 10, 10, func(x, y float64) *color.Color {
+		return color.NewColor(0, 1, 0)
+	}, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, want.Colors, got.Colors)
+	assert.Equal(t, want.Alpha, got.Alpha)
+}
+
+func TestCheckpointMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "render.ckpt")
+	opts := Options{TileSize: 4, Checkpoint: &Checkpoint{Path: path, Tag: "a"}}
+	_, _, err := RenderPasses(context.Background(), 8, 8, diskPasses, []Pass{Depth}, opts)
+	assert.Nil(t, err)
+
+	tests := map[string]struct {
+		w, h     int
+		tileSize int
+		tag      string
+		passes   []Pass
+	}{
+		"different tag":        {w: 8, h: 8, tileSize: 4, tag: "b", passes: []Pass{Depth}},
+		"different dimensions": {w: 8, h: 9, tileSize: 4, tag: "a", passes: []Pass{Depth}},
+		"different tile size":  {w: 8, h: 8, tileSize: 2, tag: "a", passes: []Pass{Depth}},
+		"different pass count": {w: 8, h: 8, tileSize: 4, tag: "a", passes: []Pass{Depth, Albedo}},
+		"different passes":     {w: 8, h: 8, tileSize: 4, tag: "a", passes: []Pass{ObjectID}},
+		"different pass name":  {w: 8, h: 8, tileSize: 4, tag: "a", passes: []Pass{{Name: "Depth", Unfiltered: true}}},
+	}
+
+	for name, tc := range tests {
+		opts := Options{TileSize: tc.tileSize, Checkpoint: &Checkpoint{Path: path, Tag: tc.tag}}
+		_, _, err := RenderPasses(context.Background(), tc.w, tc.h, diskPasses, tc.passes, opts)
+		assert.NotNil(t, err, name)
+	}
+}
+
+func TestCheckpointCorruptHeader(t *testing.T) {
+	valid := checkpointHeader{Version: checkpointVersion, Width: 8, Height: 8, TileSize: 4, Layers: 1, TotalTiles: 4}
+	tests := map[string]struct {
+		corrupt func(h *checkpointHeader)
+		// tiles are indices of finished tiles written after the header, each
+		// followed by its pixels
+		tiles []uint32
+	}{
+		"huge tag":              {corrupt: func(h *checkpointHeader) { h.TagLength = math.MaxUint32 }},
+		"too many finished":     {corrupt: func(h *checkpointHeader) { h.FinishedTiles = 5 }},
+		"huge dimensions":       {corrupt: func(h *checkpointHeader) { h.Width, h.Height = math.MaxUint32, math.MaxUint32 }},
+		"missing finished tile": {corrupt: func(h *checkpointHeader) { h.FinishedTiles = 1 }},
+		"duplicate tile":        {corrupt: func(h *checkpointHeader) { h.FinishedTiles = 2 }, tiles: []uint32{1, 1}},
+	}
+
+	for name, tc := range tests {
+		header := valid
+		tc.corrupt(&header)
+		var buf bytes.Buffer
+		buf.WriteString(checkpointMagic)
+		assert.Nil(t, binary.Write(&buf, binary.LittleEndian, header), name)
+		for _, idx := range tc.tiles {
+			assert.Nil(t, binary.Write(&buf, binary.LittleEndian, idx), name)
+			assert.Nil(t, binary.Write(&buf, binary.LittleEndian, make([]float64, 4*4*4)), name)
+		}
+		path := filepath.Join(t.TempDir(), "render.ckpt")
+		assert.Nil(t, os.WriteFile(path, buf.Bytes(), 0644), name)
+
+		_, err := Render(context.Background(), 8, 8, gradient, Options{TileSize: 4, Checkpoint: &Checkpoint{Path: path}})
+		assert.NotNil(t, err, name)
+	}
+}
@@ -0,0 +1,109 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/sampling"
+)
+
+// Pass is an extra image rendered together with the final one, also known as an
+// arbitrary output variable (AOV), e.g. depth or normals for compositing
+type Pass struct {
+	Name string
+	// Unfiltered passes take the value of the sample closest to the center of
+	// every pixel instead of averaging samples, which keeps values that can't be
+	// blended, like object IDs, exact on the edges of objects
+	Unfiltered bool
+}
+
+// Standard passes. Scalar values are stored in all three components of a color,
+// vectors are stored as red, green and blue.
+var (
+	// Depth is the distance from the camera to the hit point
+	Depth = Pass{Name: "depth", Unfiltered: true}
+	// Normal is the world space surface normal at the hit point
+	Normal = Pass{Name: "normal"}
+	// Albedo is the surface color before lighting
+	Albedo = Pass{Name: "albedo"}
+	// ObjectID identifies the object or material that was hit
+	ObjectID = Pass{Name: "id", Unfiltered: true}
+	// Shadow is 1 where the hit point is in shadow of every light and 0 where
+	// it's fully lit
+	Shadow = Pass{Name: "shadow"}
+	// Reflection is the part of the color that comes from reflections
+	Reflection = Pass{Name: "reflection"}
+)
+
+// StandardPasses lists every standard pass in the order they are usually stored
+var StandardPasses = []Pass{Depth, Normal, Albedo, ObjectID, Shadow, Reflection}
+
+// AOVs receives values of passes for a single sample, keyed by pass name
+type AOVs map[string]*color.Color
+
+// SetValue stores a scalar value of pass p
+func (a AOVs) SetValue(p Pass, v float64) {
+	a[p.Name] = color.NewColor(v, v, v)
+}
+
+// PassShader works like Shader, but also stores values of passes in aovs.
+// Passes that aren't set are treated as misses, the same way as a nil color.
+type PassShader func(x, y float64, aovs AOVs) *color.Color
+
+// RenderPasses renders the final image together with passes in a single run and
+// returns the passes as layers in the requested order. Everything else, including
+// the checkpoint which stores passes too, works the same way as in Render.
+func RenderPasses(ctx context.Context, w, h int, shade PassShader, passes []Pass, opts Options) (*canvas.Canvas, []canvas.Layer, error) {
+	names := make(map[string]bool)
+	passNames := make([]string, len(passes))
+	for i, p := range passes {
+		if p.Name == "" {
+			return nil, nil, errors.New("pass name can't be empty")
+		}
+		if names[p.Name] {
+			return nil, nil, fmt.Errorf("pass %s is requested more than once", p.Name)
+		}
+		names[p.Name] = true
+		passNames[i] = p.Name
+	}
+
+	layers, err := renderLayers(ctx, w, h, passNames, opts, func(layers []*canvas.Canvas, x, y int) int {
+		var points []sampling.Point
+		var values []AOVs
+		col, alpha, samples := opts.Sampler.PixelSamples(x, y, func(sx, sy float64) *color.Color {
+			aovs := AOVs{}
+			c := shade(sx, sy, aovs)
+			points = append(points, sampling.Point{X: sx - float64(x), Y: sy - float64(y)})
+			values = append(values, aovs)
+			return c
+		})
+		layers[0].WritePixelAlpha(x, y, col, alpha)
+
+		passValues := make([]*color.Color, len(values))
+		for i, p := range passes {
+			for j, aovs := range values {
+				passValues[j] = aovs[p.Name]
+			}
+			var v *color.Color
+			var a float64
+			if p.Unfiltered {
+				v, a = sampling.Nearest(points, passValues)
+			} else {
+				v, a = opts.Sampler.Average(points, passValues)
+			}
+			layers[i+1].WritePixelAlpha(x, y, v, a)
+		}
+		return samples
+	})
+	if layers == nil {
+		return nil, nil, err
+	}
+	result := make([]canvas.Layer, len(passes))
+	for i, p := range passes {
+		result[i] = canvas.Layer{Name: p.Name, Canvas: layers[i+1]}
+	}
+	return layers[0], result, err
+}
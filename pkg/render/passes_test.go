@@ -0,0 +1,90 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/sampling"
+	"github.com/stretchr/testify/assert"
+)
+
+// diskPasses shades disk and stores its depth, ID and albedo
+func diskPasses(x, y float64, aovs AOVs) *color.Color {
+	c := disk(x, y)
+	if c != nil {
+		aovs.SetValue(Depth, 2+x/10)
+		aovs.SetValue(ObjectID, 7)
+		aovs[Albedo.Name] = color.NewColor(0, 0, 1)
+	}
+	return c
+}
+
+func TestRenderPasses(t *testing.T) {
+	opts := Options{Sampler: sampling.Sampler{Samples: 16}}
+	want, err := Render(context.Background(), 10, 10, disk, opts)
+	assert.Nil(t, err)
+
+	beauty, passes, err := RenderPasses(context.Background(), 10, 10, diskPasses, []Pass{Depth, ObjectID, Albedo, Normal}, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, want.Colors, beauty.Colors)
+	assert.Equal(t, want.Alpha, beauty.Alpha)
+	assert.Len(t, passes, 4)
+	for i, name := range []string{"depth", "id", "albedo", "normal"} {
+		assert.Equal(t, name, passes[i].Name)
+	}
+
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			// Unfiltered IDs are either exact or missing, even on the edges
+			id, _ := passes[1].Canvas.GetPixel(x, y)
+			idAlpha, _ := passes[1].Canvas.GetAlpha(x, y)
+			if idAlpha == 1 {
+				assert.True(t, color.Equals(id, color.NewColor(7, 7, 7)), "pixel %d %d", x, y)
+			} else {
+				assert.Equal(t, 0.0, idAlpha)
+			}
+
+			// Filtered passes cover the same part of the pixel as the image
+			albedoAlpha, _ := passes[2].Canvas.GetAlpha(x, y)
+			assert.InDelta(t, want.Alpha[y][x], albedoAlpha, 1e-9)
+			normalAlpha, _ := passes[3].Canvas.GetAlpha(x, y)
+			assert.Equal(t, 0.0, normalAlpha)
+		}
+	}
+	depth, _ := passes[0].Canvas.GetPixel(5, 5)
+	assert.InDelta(t, 2.5, depth.Red, 0.05)
+}
+
+func TestRenderPassesErrors(t *testing.T) {
+	_, _, err := RenderPasses(context.Background(), 4, 4, diskPasses, []Pass{Depth, Depth}, Options{})
+	assert.NotNil(t, err)
+	_, _, err = RenderPasses(context.Background(), 4, 4, diskPasses, []Pass{{}}, Options{})
+	assert.NotNil(t, err)
+	_, _, err = RenderPasses(context.Background(), 0, 4, diskPasses, nil, Options{})
+	assert.NotNil(t, err)
+}
+
+func TestRenderPassesCheckpoint(t *testing.T) {
+	cp := &Checkpoint{Path: filepath.Join(t.TempDir(), "render.ckpt")}
+	opts := Options{TileSize: 4, Workers: 1, Checkpoint: cp}
+	_, want, err := RenderPasses(context.Background(), 10, 10, diskPasses, []Pass{Depth, Albedo}, opts)
+	assert.Nil(t, err)
+
+	// Everything is resumed from the checkpoint, including passes
+	_, got, err := RenderPasses(context.Background(), 10, 10, func(x, y float64, aovs AOVs) *color.Color {
+		return nil
+	}, []Pass{Depth, Albedo}, opts)
+	assert.Nil(t, err)
+	for i := range want {
+		assert.Equal(t, want[i].Canvas.Colors, got[i].Canvas.Colors)
+		assert.Equal(t, want[i].Canvas.Alpha, got[i].Canvas.Alpha)
+	}
+
+	// Checkpoint with a different set of passes can't be resumed
+	_, _, err = RenderPasses(context.Background(), 10, 10, diskPasses, []Pass{Depth}, opts)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrInterrupted))
+}
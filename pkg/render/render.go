@@ -0,0 +1,333 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/sampling"
+)
+
+// DefaultTileSize is the side of a square tile used when Options.TileSize is not set
+const DefaultTileSize = 16
+
+// ErrInterrupted is returned together with a partially rendered canvas when the
+// context is cancelled before every tile is done
+var ErrInterrupted = errors.New("render interrupted")
+
+// Shader returns the color visible at point (x, y) of the image, where the pixel
+// at [w][h] covers the area from (w, h) to (w+1, h+1). It returns nil if nothing
+// is visible there, which makes that part of the pixel transparent.
+type Shader func(x, y float64) *color.Color
+
+// Options configures how the image is rendered
+type Options struct {
+	// Workers is the number of tiles rendered concurrently, runtime.NumCPU() if 0
+	Workers int
+	// TileSize is the side of a square tile in pixels, DefaultTileSize if 0
+	TileSize int
+	// Sampler configures how many samples are taken per pixel and how they are
+	// combined, zero value takes a single sample at the center of every pixel
+	Sampler sampling.Sampler
+	// SampleHeatmap, if set, receives a debug image of how many samples every pixel
+	// took, going from blue for a single sample to red for the sampler maximum.
+	// It must have the same dimensions as the rendered image. Pixels resumed from
+	// a checkpoint are left untouched.
+	SampleHeatmap *canvas.Canvas
+	// Progress, if set, is called after every finished tile. Calls are never made
+	// concurrently, so the callback doesn't need to be synchronized.
+	Progress func(Progress)
+	// Checkpoint, if set, makes the render periodically save finished tiles and
+	// resume from the saved file instead of rendering them again
+	Checkpoint *Checkpoint
+}
+
+// Progress describes how far along the render is
+type Progress struct {
+	TilesDone, TilesTotal   int
+	PixelsDone, PixelsTotal int
+	// Elapsed is the time since the render started
+	Elapsed time.Duration
+	// ETA is the estimated time until the render is done based on the average
+	// speed so far, 0 until the first tile is finished
+	ETA time.Duration
+}
+
+// Fraction returns the part of pixels that are done as a number between 0 and 1
+func (p Progress) Fraction() float64 {
+	if p.PixelsTotal == 0 {
+		return 0
+	}
+	return float64(p.PixelsDone) / float64(p.PixelsTotal)
+}
+
+// Tile is a rectangular region of the image that is rendered as a single unit of work
+type Tile struct {
+	X, Y, Width, Height int
+}
+
+// Tiles splits an image of dimensions w x h into tiles of size x size pixels going
+// row by row from the top left corner. Tiles on the right and bottom edges are
+// cropped to the image. It returns nil if size isn't positive.
+func Tiles(w, h, size int) []Tile {
+	if size <= 0 {
+		return nil
+	}
+	var tiles []Tile
+	for y := 0; y < h; y += size {
+		for x := 0; x < w; x += size {
+			tiles = append(tiles, Tile{
+				X:      x,
+				Y:      y,
+				Width:  minInt(size, w-x),
+				Height: minInt(size, h-y),
+			})
+		}
+	}
+	return tiles
+}
+
+// Render creates canvas of dimensions w x h and fills it by calling shade for
+// every sample of every pixel. Tiles are rendered concurrently, but every pixel
+// only depends on its own coordinates and the sampler seed, so the result is
+// identical for any number of workers. If ctx is cancelled, tiles that were
+// already started are finished and the partially rendered canvas is returned
+// with an error wrapping ErrInterrupted.
+func Render(ctx context.Context, w, h int, shade Shader, opts Options) (*canvas.Canvas, error) {
+	layers, err := renderLayers(ctx, w, h, nil, opts, func(layers []*canvas.Canvas, x, y int) int {
+		col, alpha, samples := opts.Sampler.PixelSamples(x, y, shade)
+		layers[0].WritePixelAlpha(x, y, col, alpha)
+		return samples
+	})
+	if layers == nil {
+		return nil, err
+	}
+	return layers[0], err
+}
+
+// pixelFunc renders the pixel at [x][y] into every layer and returns the number
+// of samples it took
+type pixelFunc func(layers []*canvas.Canvas, x, y int) int
+
+// renderLayers does the work of Render for the final image and a canvas for every
+// one of passes at once, all of them are filled by pixel and saved to the
+// checkpoint together
+func renderLayers(ctx context.Context, w, h int, passes []string, opts Options, pixel pixelFunc) ([]*canvas.Canvas, error) {
+	if w <= 0 || h <= 0 {
+		return nil, errors.New("image dimensions must be positive")
+	}
+	if opts.SampleHeatmap != nil && (opts.SampleHeatmap.Width != w || opts.SampleHeatmap.Height != h) {
+		return nil, errors.New("sample heatmap dimensions don't match the image")
+	}
+	tileSize := opts.TileSize
+	if tileSize <= 0 {
+		tileSize = DefaultTileSize
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	layers := make([]*canvas.Canvas, len(passes)+1)
+	for i := range layers {
+		layers[i] = canvas.NewCanvas(w, h)
+	}
+	tiles := Tiles(w, h, tileSize)
+	tracker := newProgressTracker(layers, passes, tiles, tileSize, opts)
+	if err := tracker.resume(); err != nil {
+		return nil, err
+	}
+	if workers > len(tiles) {
+		workers = len(tiles)
+	}
+
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+				renderTile(layers, tiles[idx], pixel, opts)
+				tracker.tileDone(idx)
+			}
+		}()
+	}
+
+	var err error
+	for idx := range tiles {
+		if tracker.isDone(idx) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case queue <- idx:
+		}
+		if err != nil {
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+	if err == nil && !tracker.finished() {
+		err = ctx.Err()
+	}
+	if saveErr := tracker.save(); saveErr != nil && err == nil {
+		return layers, saveErr
+	}
+	if err != nil {
+		return layers, fmt.Errorf("%w: %v", ErrInterrupted, err)
+	}
+	return layers, nil
+}
+
+// renderTile writes every pixel of tile t to layers. Different tiles never share
+// pixels, so they can be rendered into the same canvases at the same time.
+func renderTile(layers []*canvas.Canvas, t Tile, pixel pixelFunc, opts Options) {
+	maxSamples := opts.Sampler.MaxSamples()
+	for y := t.Y; y < t.Y+t.Height; y++ {
+		for x := t.X; x < t.X+t.Width; x++ {
+			samples := pixel(layers, x, y)
+			if opts.SampleHeatmap != nil {
+				opts.SampleHeatmap.WritePixel(x, y, heatmapColor(samples, maxSamples))
+			}
+		}
+	}
+}
+
+// heatmapColor goes through the hues from blue for a single sample to red for max
+// samples
+func heatmapColor(samples, max int) *color.Color {
+	t := 0.0
+	if max > 1 {
+		t = float64(samples-1) / float64(max-1)
+	}
+	return color.FromHSV(240*(1-t), 1, 1)
+}
+
+// progressTracker keeps track of finished tiles, reports progress and saves checkpoints
+type progressTracker struct {
+	mu       sync.Mutex
+	start    time.Time
+	progress Progress
+	report   func(Progress)
+
+	layers []*canvas.Canvas
+	// passes are the names of layers after the first one
+	passes   []string
+	tiles    []Tile
+	tileSize int
+	done     []bool
+	// resumedPixels are not counted towards the speed of the render when estimating ETA
+	resumedPixels int
+
+	checkpoint *Checkpoint
+	lastSave   time.Time
+}
+
+func newProgressTracker(layers []*canvas.Canvas, passes []string, tiles []Tile, tileSize int, opts Options) *progressTracker {
+	return &progressTracker{
+		start: time.Now(),
+		progress: Progress{
+			TilesTotal:  len(tiles),
+			PixelsTotal: layers[0].Width * layers[0].Height,
+		},
+		report:     opts.Progress,
+		layers:     layers,
+		passes:     passes,
+		tiles:      tiles,
+		tileSize:   tileSize,
+		done:       make([]bool, len(tiles)),
+		checkpoint: opts.Checkpoint,
+		lastSave:   time.Now(),
+	}
+}
+
+// resume marks tiles stored in the checkpoint file as done and copies their pixels
+// to the canvases. It's not an error if the checkpoint file doesn't exist yet.
+func (pt *progressTracker) resume() error {
+	if pt.checkpoint == nil {
+		return nil
+	}
+	done, err := pt.checkpoint.load(pt.layers, pt.passes, pt.tiles, pt.tileSize)
+	if err != nil {
+		return err
+	}
+	for _, idx := range done {
+		pt.done[idx] = true
+		pt.progress.TilesDone++
+		pt.progress.PixelsDone += pt.tiles[idx].Width * pt.tiles[idx].Height
+	}
+	pt.resumedPixels = pt.progress.PixelsDone
+	return nil
+}
+
+func (pt *progressTracker) tileDone(idx int) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	t := pt.tiles[idx]
+	pt.done[idx] = true
+	p := &pt.progress
+	p.TilesDone++
+	p.PixelsDone += t.Width * t.Height
+	p.Elapsed = time.Since(pt.start)
+	p.ETA = estimateRemaining(p.Elapsed, p.PixelsDone-pt.resumedPixels, p.PixelsTotal-pt.resumedPixels)
+	if pt.report != nil {
+		pt.report(*p)
+	}
+	if pt.checkpoint != nil && time.Since(pt.lastSave) >= pt.checkpoint.interval() {
+		// Failing to save an intermediate checkpoint shouldn't stop the render,
+		// the final save in Render reports the error
+		if err := pt.saveLocked(); err == nil {
+			pt.lastSave = time.Now()
+		}
+	}
+}
+
+func (pt *progressTracker) isDone(idx int) bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.done[idx]
+}
+
+func (pt *progressTracker) finished() bool {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.progress.TilesDone == pt.progress.TilesTotal
+}
+
+func (pt *progressTracker) save() error {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	return pt.saveLocked()
+}
+
+func (pt *progressTracker) saveLocked() error {
+	if pt.checkpoint == nil {
+		return nil
+	}
+	return pt.checkpoint.save(pt.layers, pt.passes, pt.tiles, pt.tileSize, pt.done)
+}
+
+func estimateRemaining(elapsed time.Duration, done, total int) time.Duration {
+	if done == 0 {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * float64(total-done) / float64(done))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,214 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas/canvastest"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/sampling"
+	"github.com/stretchr/testify/assert"
+)
+
+func gradient(x, y float64) *color.Color {
+	return color.NewColor(math.Sin(x*0.1), math.Cos(y*0.07), math.Sin(x*y*0.001))
+}
+
+func renderSequential(w, h int, shade Shader) *canvas.Canvas {
+	c := canvas.NewCanvas(w, h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c.WritePixel(x, y, shade(float64(x)+0.5, float64(y)+0.5))
+		}
+	}
+	return c
+}
+
+func TestTiles(t *testing.T) {
+	tiles := Tiles(5, 3, 2)
+	assert.Equal(t, []Tile{
+		{X: 0, Y: 0, Width: 2, Height: 2},
+		{X: 2, Y: 0, Width: 2, Height: 2},
+		{X: 4, Y: 0, Width: 1, Height: 2},
+		{X: 0, Y: 2, Width: 2, Height: 1},
+		{X: 2, Y: 2, Width: 2, Height: 1},
+		{X: 4, Y: 2, Width: 1, Height: 1},
+	}, tiles)
+}
+
+func TestTilesWithInvalidSize(t *testing.T) {
+	assert.Nil(t, Tiles(5, 3, 0))
+	assert.Nil(t, Tiles(5, 3, -2))
+}
+
+func TestRenderMatchesSequential(t *testing.T) {
+	want := renderSequential(67, 41, gradient)
+
+	tests := map[string]Options{
+		"default":       {},
+		"single worker": {Workers: 1},
+		"many workers":  {Workers: 16, TileSize: 5},
+		"huge tile":     {Workers: 4, TileSize: 1000},
+	}
+
+	for name, opts := range tests {
+		got, err := Render(context.Background(), 67, 41, gradient, opts)
+		assert.Nil(t, err, name)
+		// Pixels have to be bit-identical, not just equal within epsilon
+		assert.Equal(t, want.Colors, got.Colors, name)
+	}
+}
+
+func TestRenderSupersamplingIsDeterministic(t *testing.T) {
+	s := sampling.Sampler{Samples: 9, Pattern: sampling.Jittered{}, Filter: sampling.Gaussian{}, Seed: 7}
+	want, err := Render(context.Background(), 30, 20, gradient, Options{Workers: 1, Sampler: s})
+	assert.Nil(t, err)
+	got, err := Render(context.Background(), 30, 20, gradient, Options{Workers: 8, TileSize: 3, Sampler: s})
+	assert.Nil(t, err)
+	assert.Equal(t, want.Colors, got.Colors)
+
+	s.Seed = 8
+	other, err := Render(context.Background(), 30, 20, gradient, Options{Sampler: s})
+	assert.Nil(t, err)
+	assert.NotEqual(t, want.Colors, other.Colors)
+}
+
+func TestRenderSampleHeatmap(t *testing.T) {
+	// Only the column of pixels at x = 2 contains an edge
+	edge := func(x, y float64) *color.Color {
+		if x < 2.5 {
+			return color.NewColor(1, 1, 1)
+		}
+		return color.NewColor(0, 0, 0)
+	}
+	heatmap := canvas.NewCanvas(5, 3)
+	opts := Options{
+		Sampler:       sampling.Sampler{Adaptive: &sampling.Adaptive{MaxSamples: 16}},
+		SampleHeatmap: heatmap,
+	}
+	_, err := Render(context.Background(), 5, 3, edge, opts)
+	assert.Nil(t, err)
+
+	blue := color.NewColor(0, 0, 1)
+	red := color.NewColor(1, 0, 0)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 5; x++ {
+			p, _ := heatmap.GetPixel(x, y)
+			if x == 2 {
+				assert.True(t, color.Equals(p, red), "pixel [%d][%d] is %v", x, y, p)
+			} else {
+				// Flat pixels take 4 out of 16 samples
+				assert.True(t, color.Equals(p, color.FromHSV(240*(1-3.0/15), 1, 1)), "pixel [%d][%d] is %v", x, y, p)
+			}
+		}
+	}
+
+	_, err = Render(context.Background(), 4, 3, edge, opts)
+	assert.NotNil(t, err)
+	assert.True(t, color.Equals(heatmapColor(1, 1), blue))
+}
+
+// disk is visible only inside of a circle in the middle of a 10 x 10 image
+func disk(x, y float64) *color.Color {
+	if (x-5)*(x-5)+(y-5)*(y-5) > 16 {
+		return nil
+	}
+	return color.NewColor(1, 0, 0)
+}
+
+func TestRenderTransparent(t *testing.T) {
+	c, err := Render(context.Background(), 10, 10, disk, Options{Sampler: sampling.Sampler{Samples: 16}})
+	assert.Nil(t, err)
+
+	a, _ := c.GetAlpha(0, 0)
+	assert.Equal(t, 0.0, a)
+	p, _ := c.GetPixel(0, 0)
+	assert.True(t, color.Equals(p, color.NewColor(0, 0, 0)))
+	a, _ = c.GetAlpha(5, 5)
+	assert.Equal(t, 1.0, a)
+
+	// Pixels on the edge of the disk are partially covered and premultiplied
+	a, _ = c.GetAlpha(8, 7)
+	assert.True(t, a > 0 && a < 1, "alpha %v", a)
+	p, _ = c.GetPixel(8, 7)
+	assert.InDelta(t, a, p.Red, 1e-9)
+}
+
+func TestRenderGolden(t *testing.T) {
+	s := sampling.Sampler{Samples: 16, Pattern: sampling.Sobol{}, Filter: sampling.NewMitchell(), Seed: 1}
+	c, err := Render(context.Background(), 48, 32, gradient, Options{Sampler: s})
+	assert.Nil(t, err)
+	canvastest.AssertGolden(t, c, "testdata/gradient.png", 1.0/255)
+}
+
+func TestRenderInvalidDimensions(t *testing.T) {
+	_, err := Render(context.Background(), 0, 10, gradient, Options{})
+	assert.NotNil(t, err)
+}
+
+func TestRenderCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c, err := Render(ctx, 64, 64, gradient, Options{Workers: 2})
+	assert.True(t, errors.Is(err, ErrInterrupted))
+	assert.NotNil(t, c)
+}
+
+func TestRenderProgress(t *testing.T) {
+	var reports []Progress
+	opts := Options{
+		Workers:  3,
+		TileSize: 4,
+		Progress: func(p Progress) { reports = append(reports, p) },
+	}
+	_, err := Render(context.Background(), 10, 9, gradient, opts)
+	assert.Nil(t, err)
+
+	assert.Len(t, reports, 9)
+	for i, p := range reports {
+		assert.Equal(t, i+1, p.TilesDone)
+		assert.Equal(t, 9, p.TilesTotal)
+		assert.Equal(t, 90, p.PixelsTotal)
+	}
+	last := reports[len(reports)-1]
+	assert.Equal(t, 90, last.PixelsDone)
+	assert.Equal(t, 1.0, last.Fraction())
+	assert.Equal(t, time.Duration(0), last.ETA)
+}
+
+func TestRenderInterruptedReturnsPartialCanvas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	white := color.NewColor(1, 1, 1)
+	opts := Options{
+		Workers:  1,
+		TileSize: 2,
+		Progress: func(p Progress) {
+			if p.TilesDone == 3 {
+				cancel()
+			}
+		},
+	}
+	c, err := Render(ctx, 8, 8, func(x, y float64) *color.Color { return white }, opts)
+	assert.True(t, errors.Is(err, ErrInterrupted))
+
+	done := 0
+	for _, row := range c.Colors {
+		for _, pix := range row {
+			if color.Equals(pix, white) {
+				done++
+			}
+		}
+	}
+	// The tile being handed to the worker when the callback fires may still finish
+	assert.True(t, done >= 12 && done < 64, "rendered %d pixels", done)
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	assert.Equal(t, time.Duration(0), estimateRemaining(time.Second, 0, 10))
+	assert.Equal(t, 3*time.Second, estimateRemaining(time.Second, 25, 100))
+}
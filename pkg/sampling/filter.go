@@ -0,0 +1,88 @@
+package sampling
+
+import (
+	"math"
+)
+
+// Filter weighs a sample by its offset (dx, dy) from the center of the pixel, both
+// offsets are measured in pixels. Samples only contribute to the pixel they are
+// taken in, so filters are evaluated for offsets of at most half a pixel and
+// never blur neighbouring pixels together. Filters reaching further than that
+// only make the weights inside of the pixel flatter.
+type Filter interface {
+	Weight(dx, dy float64) float64
+}
+
+// Box gives every sample the same weight, which is a plain average
+type Box struct{}
+
+// Weight always returns 1
+func (Box) Weight(dx, dy float64) float64 {
+	return 1
+}
+
+// Tent weighs samples linearly decreasing from the center of the pixel down to 0
+// at distance Radius, 1 pixel if Radius is 0
+type Tent struct {
+	Radius float64
+}
+
+// Weight returns the product of tent functions along both axes
+func (t Tent) Weight(dx, dy float64) float64 {
+	r := t.Radius
+	if r <= 0 {
+		r = 1
+	}
+	return math.Max(r-math.Abs(dx), 0) * math.Max(r-math.Abs(dy), 0)
+}
+
+// Gaussian weighs samples with a Gaussian of standard deviation Sigma in pixels,
+// 0.5 if Sigma is 0
+type Gaussian struct {
+	Sigma float64
+}
+
+// Weight returns the value of the Gaussian at the sample offset
+func (g Gaussian) Weight(dx, dy float64) float64 {
+	s := g.Sigma
+	if s <= 0 {
+		s = 0.5
+	}
+	return math.Exp(-(dx*dx + dy*dy) / (2 * s * s))
+}
+
+// Mitchell is the Mitchell-Netravali filter scaled to reach 0 at distance Radius,
+// 0.5 pixels if Radius is 0. The zero value has both B and C set to 0, use
+// NewMitchell for the recommended values.
+type Mitchell struct {
+	B, C   float64
+	Radius float64
+}
+
+// NewMitchell returns the Mitchell-Netravali filter with the recommended B and C
+// of 1/3 covering a single pixel
+func NewMitchell() Mitchell {
+	return Mitchell{B: 1.0 / 3, C: 1.0 / 3}
+}
+
+// Weight returns the product of Mitchell-Netravali curves along both axes
+func (m Mitchell) Weight(dx, dy float64) float64 {
+	r := m.Radius
+	if r <= 0 {
+		r = 0.5
+	}
+	// The curves are defined up to distance 2
+	return mitchell1D(2*dx/r, m.B, m.C) * mitchell1D(2*dy/r, m.B, m.C)
+}
+
+func mitchell1D(x, b, c float64) float64 {
+	x = math.Abs(x)
+	switch {
+	case x < 1:
+		return ((12-9*b-6*c)*x*x*x + (-18+12*b+6*c)*x*x + (6 - 2*b)) / 6
+	case x < 2:
+		return ((-b-6*c)*x*x*x + (6*b+30*c)*x*x + (-12*b-48*c)*x + (8*b + 24*c)) / 6
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,160 @@
+package sampling
+
+import (
+	"math"
+)
+
+// Point is a sample position inside of a pixel, both coordinates are in [0, 1)
+// range with (0, 0) being the top left corner of the pixel
+type Point struct {
+	X, Y float64
+}
+
+// Pattern generates positions of samples inside of a pixel. The same pixel, count
+// and seed always produce the same samples.
+type Pattern interface {
+	Samples(x, y, n int, seed int64) []Point
+}
+
+// Grid places samples in the centers of cells of a regular k x k grid, where k is
+// the square root of the sample count rounded up. A single sample is placed at the
+// center of the pixel.
+type Grid struct{}
+
+// Samples returns positions of grid cell centers
+func (Grid) Samples(x, y, n int, seed int64) []Point {
+	k := gridSize(n)
+	points := make([]Point, 0, k*k)
+	for j := 0; j < k; j++ {
+		for i := 0; i < k; i++ {
+			points = append(points, Point{(float64(i) + 0.5) / float64(k), (float64(j) + 0.5) / float64(k)})
+		}
+	}
+	return points
+}
+
+// Jittered divides the pixel into a k x k grid the same way as Grid does, but
+// places each sample at a random position inside of its cell
+type Jittered struct{}
+
+// Samples returns one randomly placed position per grid cell
+func (Jittered) Samples(x, y, n int, seed int64) []Point {
+	k := gridSize(n)
+	r := newRNG(seed, x, y)
+	points := make([]Point, 0, k*k)
+	for j := 0; j < k; j++ {
+		for i := 0; i < k; i++ {
+			points = append(points, Point{(float64(i) + r.float64()) / float64(k), (float64(j) + r.float64()) / float64(k)})
+		}
+	}
+	return points
+}
+
+// Halton uses the Halton sequence in bases 2 and 3. Every pixel gets the sequence
+// shifted by its own random offset, so neighbouring pixels don't share the same
+// sample positions.
+type Halton struct{}
+
+// Samples returns the first n points of the shifted Halton sequence
+func (Halton) Samples(x, y, n int, seed int64) []Point {
+	r := newRNG(seed, x, y)
+	dx, dy := r.float64(), r.float64()
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = Point{
+			wrap(radicalInverse(2, i+1) + dx),
+			wrap(radicalInverse(3, i+1) + dy),
+		}
+	}
+	return points
+}
+
+// Sobol uses the first two dimensions of the Sobol sequence, scrambled for every
+// pixel by a random digital shift
+type Sobol struct{}
+
+// Samples returns the first n points of the scrambled Sobol sequence
+func (Sobol) Samples(x, y, n int, seed int64) []Point {
+	r := newRNG(seed, x, y)
+	sx, sy := uint32(r.next()), uint32(r.next())
+	points := make([]Point, n)
+	for i := range points {
+		points[i] = Point{
+			float64(vanDerCorput(uint32(i))^sx) / (1 << 32),
+			float64(sobol2(uint32(i))^sy) / (1 << 32),
+		}
+	}
+	return points
+}
+
+func gridSize(n int) int {
+	if n < 1 {
+		return 1
+	}
+	return int(math.Ceil(math.Sqrt(float64(n))))
+}
+
+func radicalInverse(base, i int) float64 {
+	result := 0.0
+	f := 1.0 / float64(base)
+	for ; i > 0; i /= base {
+		result += f * float64(i%base)
+		f /= float64(base)
+	}
+	return result
+}
+
+func wrap(x float64) float64 {
+	return x - math.Floor(x)
+}
+
+// vanDerCorput returns bits of i in reverse order, which is the first dimension
+// of the Sobol sequence
+func vanDerCorput(i uint32) uint32 {
+	i = (i << 16) | (i >> 16)
+	i = ((i & 0x00ff00ff) << 8) | ((i & 0xff00ff00) >> 8)
+	i = ((i & 0x0f0f0f0f) << 4) | ((i & 0xf0f0f0f0) >> 4)
+	i = ((i & 0x33333333) << 2) | ((i & 0xcccccccc) >> 2)
+	i = ((i & 0x55555555) << 1) | ((i & 0xaaaaaaaa) >> 1)
+	return i
+}
+
+// sobol2 returns the second dimension of the Sobol sequence
+func sobol2(i uint32) uint32 {
+	var result uint32
+	for v := uint32(1) << 31; i != 0; i >>= 1 {
+		if i&1 != 0 {
+			result ^= v
+		}
+		v ^= v >> 1
+	}
+	return result
+}
+
+// rng is a small splitmix64 generator. It's much cheaper to create than
+// math/rand sources, which matters because every pixel gets its own generator.
+type rng struct {
+	state uint64
+}
+
+func newRNG(seed int64, x, y int) *rng {
+	r := &rng{uint64(seed)}
+	r.state ^= r.mix(uint64(x)*0x9e3779b97f4a7c15 + uint64(y))
+	return r
+}
+
+func (r *rng) next() uint64 {
+	r.state += 0x9e3779b97f4a7c15
+	return r.mix(r.state)
+}
+
+func (r *rng) mix(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// float64 returns a random number in [0, 1) range
+func (r *rng) float64() float64 {
+	return float64(r.next()>>11) / (1 << 53)
+}
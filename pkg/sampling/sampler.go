@@ -0,0 +1,223 @@
+package sampling
+
+import (
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+// Sampler computes the color of a whole pixel out of several samples. Zero value
+// takes a single sample at the center of the pixel.
+type Sampler struct {
+	// Samples is the number of samples per pixel, 1 if 0. It's ignored when
+	// Adaptive is set.
+	Samples int
+	// Pattern places samples inside of the pixel, Grid if nil or Sobol if nil in
+	// adaptive mode
+	Pattern Pattern
+	// Filter weighs samples when they are averaged, Box if nil
+	Filter Filter
+	// Seed makes random patterns reproducible, the same seed always gives the
+	// same image
+	Seed int64
+	// Adaptive, if set, makes the number of samples depend on how noisy the pixel is
+	Adaptive *Adaptive
+}
+
+// Adaptive configures sampling that keeps taking samples until the pixel is
+// smooth enough. Samples are taken in the order the pattern generates them for
+// MaxSamples, so progressive patterns like Halton and Sobol should be used rather
+// than Grid or Jittered, which would sample only the top rows of the pixel first.
+type Adaptive struct {
+	// MinSamples are always taken before the noise is estimated, at least 2, 4 if 0
+	MinSamples int
+	// MaxSamples is the upper limit of samples per pixel, 64 if 0
+	MaxSamples int
+	// Threshold is the standard error of mean luminance at which the pixel is
+	// considered converged, 0.01 if 0
+	Threshold float64
+}
+
+// Pixel calls shade for every sample of the pixel at [x][y] and returns the
+// weighted average of the results. Shade returns nil for samples that don't hit
+// anything, those are transparent and the color is premultiplied by the alpha
+// of the pixel.
+func (s Sampler) Pixel(x, y int, shade func(x, y float64) *color.Color) *color.Color {
+	c, _, _ := s.PixelSamples(x, y, shade)
+	return c
+}
+
+// PixelSamples works like Pixel, but also returns the alpha of the pixel, i.e.
+// the weighted part of samples that hit something, and the number of samples taken
+func (s Sampler) PixelSamples(x, y int, shade func(x, y float64) *color.Color) (*color.Color, float64, int) {
+	if s.Adaptive != nil {
+		return s.adaptivePixel(x, y, shade)
+	}
+	pattern := s.Pattern
+	if pattern == nil {
+		pattern = Grid{}
+	}
+	n := s.Samples
+	if n < 1 {
+		n = 1
+	}
+
+	points := pattern.Samples(x, y, n, s.Seed)
+	if len(points) == 1 {
+		if c := shade(float64(x)+points[0].X, float64(y)+points[0].Y); c != nil {
+			return c, 1, 1
+		}
+		return color.NewColor(0, 0, 0), 0, 1
+	}
+	acc := newAccumulator(s.Filter)
+	for _, p := range points {
+		acc.add(p, shade(float64(x)+p.X, float64(y)+p.Y))
+	}
+	c, alpha := acc.result()
+	return c, alpha, len(points)
+}
+
+// MaxSamples returns the largest number of samples Pixel can take for one pixel
+func (s Sampler) MaxSamples() int {
+	if s.Adaptive != nil {
+		_, max, _ := s.Adaptive.limits()
+		return max
+	}
+	if s.Samples < 1 {
+		return 1
+	}
+	switch s.Pattern.(type) {
+	case nil, Grid, Jittered:
+		k := gridSize(s.Samples)
+		return k * k
+	}
+	return s.Samples
+}
+
+// Average combines values computed at points inside of a pixel with the filter
+// of the sampler, the same way PixelSamples combines colors, and returns the
+// result premultiplied by alpha. Nil values are misses. It's meant for extra
+// values shaded together with the color, e.g. render passes.
+func (s Sampler) Average(points []Point, values []*color.Color) (*color.Color, float64) {
+	if len(points) == 0 {
+		return color.NewColor(0, 0, 0), 0
+	}
+	acc := newAccumulator(s.Filter)
+	for i, p := range points {
+		acc.add(p, values[i])
+	}
+	return acc.result()
+}
+
+// Nearest returns the value computed at the point closest to the center of the
+// pixel with alpha 1, or black with alpha 0 if it's a miss. Unlike Average it
+// never mixes values, which keeps things like object IDs exact.
+func Nearest(points []Point, values []*color.Color) (*color.Color, float64) {
+	nearest, distance := -1, math.Inf(1)
+	for i, p := range points {
+		if d := (p.X-0.5)*(p.X-0.5) + (p.Y-0.5)*(p.Y-0.5); d < distance {
+			nearest, distance = i, d
+		}
+	}
+	if nearest < 0 || values[nearest] == nil {
+		return color.NewColor(0, 0, 0), 0
+	}
+	return values[nearest], 1
+}
+
+func (s Sampler) adaptivePixel(x, y int, shade func(x, y float64) *color.Color) (*color.Color, float64, int) {
+	pattern := s.Pattern
+	if pattern == nil {
+		pattern = Sobol{}
+	}
+	min, max, threshold := s.Adaptive.limits()
+
+	acc := newAccumulator(s.Filter)
+	// Welford's online algorithm for the variance of luminance
+	n, mean, m2 := 0, 0.0, 0.0
+	for _, p := range pattern.Samples(x, y, max, s.Seed) {
+		c := shade(float64(x)+p.X, float64(y)+p.Y)
+		acc.add(p, c)
+
+		n++
+		// Missing the scene counts as black, so edges of objects get refined too
+		l := 0.0
+		if c != nil {
+			l = color.Luminance(c)
+		}
+		delta := l - mean
+		mean += delta / float64(n)
+		m2 += delta * (l - mean)
+
+		if n >= min && math.Sqrt(m2/float64(n-1)/float64(n)) <= threshold {
+			break
+		}
+	}
+	c, alpha := acc.result()
+	return c, alpha, n
+}
+
+func (a *Adaptive) limits() (min, max int, threshold float64) {
+	min, max, threshold = a.MinSamples, a.MaxSamples, a.Threshold
+	if min == 0 {
+		min = 4
+	}
+	if min < 2 {
+		min = 2
+	}
+	if max == 0 {
+		max = 64
+	}
+	if max < min {
+		max = min
+	}
+	if threshold <= 0 {
+		threshold = 0.01
+	}
+	return min, max, threshold
+}
+
+// accumulator computes a weighted average of samples of a single pixel together
+// with the part of samples that hit something
+type accumulator struct {
+	filter      Filter
+	weighted    *color.Color
+	plain       *color.Color
+	totalWeight float64
+	hitWeight   float64
+	count       int
+	hits        int
+}
+
+func newAccumulator(filter Filter) *accumulator {
+	if filter == nil {
+		filter = Box{}
+	}
+	return &accumulator{
+		filter:   filter,
+		weighted: color.NewColor(0, 0, 0),
+		plain:    color.NewColor(0, 0, 0),
+	}
+}
+
+func (a *accumulator) add(p Point, c *color.Color) {
+	w := a.filter.Weight(p.X-0.5, p.Y-0.5)
+	a.totalWeight += w
+	a.count++
+	if c == nil {
+		return
+	}
+	a.weighted = color.Add(a.weighted, color.Scale(c, w))
+	a.plain = color.Add(a.plain, c)
+	a.hitWeight += w
+	a.hits++
+}
+
+func (a *accumulator) result() (*color.Color, float64) {
+	// Filters like Tent can give every sample zero weight when there are only a
+	// few samples near the edges of the pixel
+	if a.totalWeight <= 0 {
+		return color.Scale(a.plain, 1/float64(a.count)), float64(a.hits) / float64(a.count)
+	}
+	return color.Scale(a.weighted, 1/a.totalWeight), a.hitWeight / a.totalWeight
+}
@@ -0,0 +1,232 @@
+package sampling
+
+import (
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatterns(t *testing.T) {
+	tests := map[string]struct {
+		pattern Pattern
+		n       int
+		want    int
+	}{
+		"grid":          {pattern: Grid{}, n: 9, want: 9},
+		"grid rounded":  {pattern: Grid{}, n: 5, want: 9},
+		"grid zero":     {pattern: Grid{}, n: 0, want: 1},
+		"jittered":      {pattern: Jittered{}, n: 16, want: 16},
+		"halton":        {pattern: Halton{}, n: 7, want: 7},
+		"sobol":         {pattern: Sobol{}, n: 32, want: 32},
+		"sobol single":  {pattern: Sobol{}, n: 1, want: 1},
+		"halton single": {pattern: Halton{}, n: 1, want: 1},
+	}
+
+	for name, tc := range tests {
+		points := tc.pattern.Samples(3, 7, tc.n, 42)
+		assert.Len(t, points, tc.want, name)
+		for _, p := range points {
+			assert.True(t, p.X >= 0 && p.X < 1 && p.Y >= 0 && p.Y < 1, "%s: %v outside of pixel", name, p)
+		}
+		assert.Equal(t, points, tc.pattern.Samples(3, 7, tc.n, 42), name)
+	}
+}
+
+func TestGridSingleSampleIsCenter(t *testing.T) {
+	assert.Equal(t, []Point{{0.5, 0.5}}, Grid{}.Samples(0, 0, 1, 0))
+	assert.Equal(t, []Point{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.75, 0.75}}, Grid{}.Samples(0, 0, 4, 0))
+}
+
+func TestRandomPatternsDependOnSeedAndPixel(t *testing.T) {
+	for _, pattern := range []Pattern{Jittered{}, Halton{}, Sobol{}} {
+		a := pattern.Samples(1, 2, 4, 1)
+		assert.NotEqual(t, a, pattern.Samples(1, 2, 4, 2))
+		assert.NotEqual(t, a, pattern.Samples(2, 1, 4, 1))
+	}
+}
+
+func TestStratification(t *testing.T) {
+	// Jittered samples and the first 4 points of the Sobol sequence both put
+	// exactly one sample in each quadrant of the pixel
+	for _, pattern := range []Pattern{Jittered{}, Sobol{}} {
+		for seed := int64(0); seed < 10; seed++ {
+			quadrants := map[[2]bool]int{}
+			for _, p := range pattern.Samples(5, 5, 4, seed) {
+				quadrants[[2]bool{p.X < 0.5, p.Y < 0.5}]++
+			}
+			assert.Len(t, quadrants, 4)
+		}
+	}
+}
+
+func TestRadicalInverse(t *testing.T) {
+	assert.Equal(t, 0.5, radicalInverse(2, 1))
+	assert.Equal(t, 0.25, radicalInverse(2, 2))
+	assert.Equal(t, 0.75, radicalInverse(2, 3))
+	assert.True(t, util.FloatEquals(radicalInverse(3, 4), 4.0/9))
+	assert.Equal(t, uint32(1<<31), vanDerCorput(1))
+	assert.Equal(t, uint32(3<<30), sobol2(2))
+}
+
+func TestFilters(t *testing.T) {
+	tests := map[string]struct {
+		filter Filter
+		dx, dy float64
+		want   float64
+	}{
+		"box":               {filter: Box{}, dx: 0.4, dy: -0.3, want: 1},
+		"tent center":       {filter: Tent{}, dx: 0, dy: 0, want: 1},
+		"tent":              {filter: Tent{}, dx: 0.5, dy: -0.5, want: 0.25},
+		"tent radius":       {filter: Tent{Radius: 2}, dx: 1, dy: 0, want: 2},
+		"tent outside":      {filter: Tent{Radius: 0.25}, dx: 0.3, dy: 0, want: 0},
+		"gaussian center":   {filter: Gaussian{}, dx: 0, dy: 0, want: 1},
+		"gaussian":          {filter: Gaussian{Sigma: 1}, dx: 1, dy: 0, want: 0.60653},
+		"mitchell center":   {filter: NewMitchell(), dx: 0, dy: 0, want: 0.79012},
+		"mitchell edge":     {filter: NewMitchell(), dx: 0.5, dy: 0, want: 0},
+		"mitchell outside":  {filter: NewMitchell(), dx: 2, dy: 0, want: 0},
+		"mitchell radius":   {filter: Mitchell{B: 1.0 / 3, C: 1.0 / 3, Radius: 2}, dx: 1, dy: 0, want: 0.04938},
+		"mitchell zero b c": {filter: Mitchell{}, dx: 0, dy: 0, want: 1},
+		"mitchell b-spline": {filter: Mitchell{B: 1}, dx: 0, dy: 0, want: 4.0 / 9},
+	}
+
+	for name, tc := range tests {
+		got := tc.filter.Weight(tc.dx, tc.dy)
+		if !util.FloatEquals(got, tc.want) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestSamplerPixel(t *testing.T) {
+	// Left half of every pixel is white, right half is black
+	edge := func(x, y float64) *color.Color {
+		if x-float64(int(x)) < 0.5 {
+			return color.NewColor(1, 1, 1)
+		}
+		return color.NewColor(0, 0, 0)
+	}
+
+	tests := map[string]struct {
+		sampler Sampler
+		want    *color.Color
+	}{
+		"zero value":   {sampler: Sampler{}, want: color.NewColor(0, 0, 0)},
+		"grid":         {sampler: Sampler{Samples: 16}, want: color.NewColor(0.5, 0.5, 0.5)},
+		"jittered":     {sampler: Sampler{Samples: 16, Pattern: Jittered{}}, want: color.NewColor(0.5, 0.5, 0.5)},
+		"tent":         {sampler: Sampler{Samples: 4, Filter: Tent{}}, want: color.NewColor(0.5, 0.5, 0.5)},
+		"zero weights": {sampler: Sampler{Samples: 4, Filter: Tent{Radius: 0.1}}, want: color.NewColor(0.5, 0.5, 0.5)},
+	}
+
+	for name, tc := range tests {
+		got := tc.sampler.Pixel(2, 3, edge)
+		if !color.Equals(got, tc.want) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+
+	var calls int
+	s := Sampler{Samples: 8, Pattern: Sobol{}, Filter: NewMitchell(), Seed: 3}
+	first := s.Pixel(0, 0, func(x, y float64) *color.Color {
+		calls++
+		return color.NewColor(x, y, 0)
+	})
+	assert.Equal(t, 8, calls)
+	second := s.Pixel(0, 0, func(x, y float64) *color.Color { return color.NewColor(x, y, 0) })
+	assert.Equal(t, first, second)
+}
+
+func TestAdaptiveSampling(t *testing.T) {
+	flat := func(x, y float64) *color.Color { return color.NewColor(0.3, 0.3, 0.3) }
+	// Vertical edge going through the middle of pixel 0
+	edge := func(x, y float64) *color.Color {
+		if x < 0.5 {
+			return color.NewColor(1, 1, 1)
+		}
+		return color.NewColor(0, 0, 0)
+	}
+
+	tests := map[string]struct {
+		sampler Sampler
+		shade   func(x, y float64) *color.Color
+		want    int
+	}{
+		"flat stops at min":         {sampler: Sampler{Adaptive: &Adaptive{}}, shade: flat, want: 4},
+		"flat custom min":           {sampler: Sampler{Adaptive: &Adaptive{MinSamples: 8}}, shade: flat, want: 8},
+		"min is at least two":       {sampler: Sampler{Adaptive: &Adaptive{MinSamples: 1}}, shade: flat, want: 2},
+		"edge reaches max":          {sampler: Sampler{Adaptive: &Adaptive{MaxSamples: 32}}, shade: edge, want: 32},
+		"edge with loose threshold": {sampler: Sampler{Adaptive: &Adaptive{MaxSamples: 32, Threshold: 1}}, shade: edge, want: 4},
+		"max below min":             {sampler: Sampler{Adaptive: &Adaptive{MinSamples: 6, MaxSamples: 3}}, shade: edge, want: 6},
+	}
+
+	for name, tc := range tests {
+		_, _, got := tc.sampler.PixelSamples(0, 0, tc.shade)
+		assert.Equal(t, tc.want, got, name)
+	}
+
+	c, _, n := Sampler{Adaptive: &Adaptive{MaxSamples: 256}}.PixelSamples(0, 0, edge)
+	assert.Equal(t, 256, n)
+	assert.True(t, util.FloatEquals(c.Red, 0.5))
+}
+
+func TestSamplerCoverage(t *testing.T) {
+	// Object covers the left half of the pixel, everything else is a miss
+	half := func(x, y float64) *color.Color {
+		if x < 0.5 {
+			return color.NewColor(1, 0.5, 0)
+		}
+		return nil
+	}
+
+	tests := map[string]struct {
+		sampler Sampler
+		want    *color.Color
+		alpha   float64
+	}{
+		"single sample miss": {sampler: Sampler{Pattern: Grid{}, Samples: 1}, want: color.NewColor(0, 0, 0), alpha: 0},
+		"grid":               {sampler: Sampler{Samples: 4}, want: color.NewColor(0.5, 0.25, 0), alpha: 0.5},
+		"gaussian":           {sampler: Sampler{Samples: 16, Filter: Gaussian{}}, want: color.NewColor(0.5, 0.25, 0), alpha: 0.5},
+		"adaptive":           {sampler: Sampler{Adaptive: &Adaptive{MaxSamples: 64}}, want: color.NewColor(0.5, 0.25, 0), alpha: 0.5},
+	}
+
+	for name, tc := range tests {
+		c, alpha, _ := tc.sampler.PixelSamples(0, 0, half)
+		if !color.Equals(c, tc.want) || !util.FloatEquals(alpha, tc.alpha) {
+			t.Fatalf("%s: expected: %v %v, got %v %v", name, tc.want, tc.alpha, c, alpha)
+		}
+	}
+
+	c, alpha, _ := Sampler{}.PixelSamples(0, 0, func(x, y float64) *color.Color { return color.NewColor(1, 1, 1) })
+	assert.True(t, color.Equals(c, color.NewColor(1, 1, 1)))
+	assert.Equal(t, 1.0, alpha)
+}
+
+func TestAverageAndNearest(t *testing.T) {
+	points := []Point{{0.25, 0.25}, {0.75, 0.25}, {0.25, 0.75}, {0.6, 0.6}}
+	values := []*color.Color{color.NewColor(1, 0, 0), nil, color.NewColor(0, 0, 1), color.NewColor(0, 1, 0)}
+
+	c, alpha := Sampler{}.Average(points, values)
+	assert.True(t, color.Equals(c, color.NewColor(0.25, 0.25, 0.25)))
+	assert.Equal(t, 0.75, alpha)
+
+	c, alpha = Nearest(points, values)
+	assert.True(t, color.Equals(c, color.NewColor(0, 1, 0)))
+	assert.Equal(t, 1.0, alpha)
+
+	values[3] = nil
+	c, alpha = Nearest(points, values)
+	assert.True(t, color.Equals(c, color.NewColor(0, 0, 0)))
+	assert.Equal(t, 0.0, alpha)
+
+	_, alpha = Sampler{}.Average(nil, nil)
+	assert.Equal(t, 0.0, alpha)
+}
+
+func TestMaxSamples(t *testing.T) {
+	assert.Equal(t, 1, Sampler{}.MaxSamples())
+	assert.Equal(t, 9, Sampler{Samples: 5}.MaxSamples())
+	assert.Equal(t, 9, Sampler{Samples: 5, Pattern: Jittered{}}.MaxSamples())
+	assert.Equal(t, 5, Sampler{Samples: 5, Pattern: Halton{}}.MaxSamples())
+	assert.Equal(t, 64, Sampler{Samples: 5, Adaptive: &Adaptive{}}.MaxSamples())
+}
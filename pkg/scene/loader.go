@@ -0,0 +1,790 @@
+package scene
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"gopkg.in/yaml.v3"
+)
+
+// Error is a problem found in a scene file together with its location
+type Error struct {
+	File         string
+	Line, Column int
+	Msg          string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// Load reads and parses the scene file at path. OBJ files included by the scene
+// are looked up relative to the directory of the scene file.
+func Load(path string) (*Scene, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, path)
+}
+
+// Parse parses a scene description. File is used in error messages and to find
+// OBJ includes relative to it.
+func Parse(data []byte, file string) (*Scene, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	p := &parser{
+		file:      file,
+		dir:       filepath.Dir(file),
+		defines:   map[string]*yaml.Node{},
+		expanding: map[string]bool{},
+	}
+	if len(doc.Content) == 0 {
+		return nil, &Error{File: file, Line: 1, Column: 1, Msg: "scene file is empty"}
+	}
+	return p.scene(doc.Content[0])
+}
+
+type parser struct {
+	file    string
+	dir     string
+	defines map[string]*yaml.Node
+	// expanding holds names of defines being expanded to detect cycles
+	expanding map[string]bool
+}
+
+func (p *parser) errorf(n *yaml.Node, format string, args ...interface{}) error {
+	return &Error{File: p.file, Line: n.Line, Column: n.Column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// fields is a mapping node with its keys indexed by name. It remembers which keys
+// were read, so that unknown keys are reported instead of being silently ignored.
+type fields struct {
+	node   *yaml.Node
+	keys   map[string]*yaml.Node
+	values map[string]*yaml.Node
+	order  []string
+	used   map[string]bool
+}
+
+func (f *fields) get(key string) (*yaml.Node, bool) {
+	f.used[key] = true
+	v, ok := f.values[key]
+	return v, ok
+}
+
+// mapping resolves references of node n and returns its fields. A mapping with
+// an "extend" key starts with all of the fields of the define it names.
+func (p *parser) mapping(n *yaml.Node) (*fields, error) {
+	n, err := p.deref(n)
+	if err != nil {
+		return nil, err
+	}
+	if n.Kind != yaml.MappingNode {
+		return nil, p.errorf(n, "expected a mapping")
+	}
+	f := &fields{
+		node:   n,
+		keys:   map[string]*yaml.Node{},
+		values: map[string]*yaml.Node{},
+		used:   map[string]bool{"extend": true},
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Value != "extend" {
+			continue
+		}
+		name, err := p.str(v)
+		if err != nil {
+			return nil, err
+		}
+		base, err := p.define(v, name)
+		if err != nil {
+			return nil, err
+		}
+		p.expanding[name] = true
+		baseFields, err := p.mapping(base)
+		delete(p.expanding, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range baseFields.order {
+			f.set(key, baseFields.keys[key], baseFields.values[key])
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if k.Value == "extend" {
+			continue
+		}
+		f.set(k.Value, k, v)
+	}
+	return f, nil
+}
+
+func (f *fields) set(key string, k, v *yaml.Node) {
+	if _, ok := f.values[key]; !ok {
+		f.order = append(f.order, key)
+	}
+	f.keys[key] = k
+	f.values[key] = v
+}
+
+// checkUnused returns an error pointing to the first key that was never read
+func (p *parser) checkUnused(f *fields) error {
+	for _, key := range f.order {
+		if !f.used[key] {
+			return p.errorf(f.keys[key], "unknown key %q", key)
+		}
+	}
+	return nil
+}
+
+// deref follows YAML aliases and names of defines until it reaches a node that
+// isn't a reference
+func (p *parser) deref(n *yaml.Node) (*yaml.Node, error) {
+	n, _, err := p.derefNames(n)
+	return n, err
+}
+
+// derefNames works like deref, but also returns names of the defines it followed
+func (p *parser) derefNames(n *yaml.Node) (*yaml.Node, []string, error) {
+	var names []string
+	seen := map[string]bool{}
+	for {
+		switch {
+		case n.Kind == yaml.AliasNode:
+			n = n.Alias
+		case n.Kind == yaml.ScalarNode && n.Tag == "!!str":
+			if _, ok := p.defines[n.Value]; !ok {
+				return n, names, nil
+			}
+			if seen[n.Value] {
+				return nil, nil, p.errorf(n, "define %q refers to itself", n.Value)
+			}
+			seen[n.Value] = true
+			d, err := p.define(n, n.Value)
+			if err != nil {
+				return nil, nil, err
+			}
+			names = append(names, n.Value)
+			n = d
+		default:
+			return n, names, nil
+		}
+	}
+}
+
+func (p *parser) define(ref *yaml.Node, name string) (*yaml.Node, error) {
+	d, ok := p.defines[name]
+	if !ok {
+		return nil, p.errorf(ref, "undefined name %q", name)
+	}
+	if p.expanding[name] {
+		return nil, p.errorf(ref, "define %q refers to itself", name)
+	}
+	return d, nil
+}
+
+func (p *parser) scene(n *yaml.Node) (*Scene, error) {
+	f, err := p.mapping(n)
+	if err != nil {
+		return nil, err
+	}
+	s := &Scene{Background: color.NewColor(0, 0, 0)}
+
+	// Defines have to be known before anything else refers to them
+	if v, ok := f.get("define"); ok {
+		if err := p.defineAll(v); err != nil {
+			return nil, err
+		}
+	}
+	v, ok := f.get("camera")
+	if !ok {
+		return nil, p.errorf(n, "scene has no camera")
+	}
+	if s.Camera, err = p.camera(v); err != nil {
+		return nil, err
+	}
+	if v, ok := f.get("background"); ok {
+		if s.Background, err = p.color(v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := f.get("lights"); ok {
+		if err := p.each(v, func(item *yaml.Node) error {
+			l, err := p.light(item)
+			s.Lights = append(s.Lights, l)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := f.get("objects"); ok {
+		if s.Objects, err = p.objects(v); err != nil {
+			return nil, err
+		}
+	}
+	return s, p.checkUnused(f)
+}
+
+func (p *parser) defineAll(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return p.errorf(n, "expected a mapping of names to definitions")
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k, v := n.Content[i], n.Content[i+1]
+		if _, ok := p.defines[k.Value]; ok {
+			return p.errorf(k, "%q is already defined", k.Value)
+		}
+		p.defines[k.Value] = v
+	}
+	return nil
+}
+
+func (p *parser) camera(n *yaml.Node) (*Camera, error) {
+	f, err := p.mapping(n)
+	if err != nil {
+		return nil, err
+	}
+	c := &Camera{Up: vector.NewVector(0, 1, 0)}
+	if c.Width, err = p.requiredInt(f, "width"); err != nil {
+		return nil, err
+	}
+	if c.Height, err = p.requiredInt(f, "height"); err != nil {
+		return nil, err
+	}
+	if c.Width <= 0 || c.Height <= 0 {
+		return nil, p.errorf(f.node, "camera dimensions must be positive")
+	}
+	fov, err := p.requiredFloat(f, "field-of-view")
+	if err != nil {
+		return nil, err
+	}
+	c.FieldOfView = degrees(fov)
+	if c.From, err = p.requiredPoint(f, "from"); err != nil {
+		return nil, err
+	}
+	if c.To, err = p.requiredPoint(f, "to"); err != nil {
+		return nil, err
+	}
+	if v, ok := f.get("up"); ok {
+		if c.Up, err = p.vector(v); err != nil {
+			return nil, err
+		}
+	}
+	if c.Transform, err = matrix.ViewTransform(c.From, c.To, c.Up); err != nil {
+		return nil, p.errorf(f.node, "invalid camera orientation: %v", err)
+	}
+	return c, p.checkUnused(f)
+}
+
+func (p *parser) light(n *yaml.Node) (*Light, error) {
+	f, err := p.mapping(n)
+	if err != nil {
+		return nil, err
+	}
+	l := &Light{Intensity: color.NewColor(1, 1, 1)}
+	if v, ok := f.get("type"); ok {
+		t, err := p.str(v)
+		if err != nil {
+			return nil, err
+		}
+		if t != "point" {
+			return nil, p.errorf(v, "unknown light type %q", t)
+		}
+	}
+	if l.Position, err = p.requiredPoint(f, "at"); err != nil {
+		return nil, err
+	}
+	if v, ok := f.get("intensity"); ok {
+		if l.Intensity, err = p.color(v); err != nil {
+			return nil, err
+		}
+	}
+	return l, p.checkUnused(f)
+}
+
+func (p *parser) objects(n *yaml.Node) ([]*Object, error) {
+	var result []*Object
+	err := p.each(n, func(item *yaml.Node) error {
+		o, err := p.object(item)
+		result = append(result, o)
+		return err
+	})
+	return result, err
+}
+
+func (p *parser) object(n *yaml.Node) (*Object, error) {
+	// Defines the object comes from stay marked as expanded until it's parsed,
+	// so that a group listing itself among its children is reported
+	n, names, err := p.derefNames(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		p.expanding[name] = true
+	}
+	defer func() {
+		for _, name := range names {
+			delete(p.expanding, name)
+		}
+	}()
+	f, err := p.mapping(n)
+	if err != nil {
+		return nil, err
+	}
+	o := &Object{
+		Transform:   matrix.Identity(4),
+		Material:    DefaultMaterial(),
+		CastsShadow: true,
+		Minimum:     math.Inf(-1),
+		Maximum:     math.Inf(1),
+		File:        p.file,
+		Line:        f.node.Line,
+	}
+	typeNode, ok := f.get("type")
+	if !ok {
+		return nil, p.errorf(f.node, "object has no type")
+	}
+	if o.Type, err = p.str(typeNode); err != nil {
+		return nil, err
+	}
+	if v, ok := f.get("transform"); ok {
+		if o.Transform, err = p.transform(v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := f.get("material"); ok {
+		if o.Material, err = p.material(v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := f.get("shadow"); ok {
+		if o.CastsShadow, err = p.bool(v); err != nil {
+			return nil, err
+		}
+	}
+
+	switch o.Type {
+	case Sphere, Plane, Cube:
+	case Cylinder, Cone:
+		if v, ok := f.get("min"); ok {
+			if o.Minimum, err = p.float(v); err != nil {
+				return nil, err
+			}
+		}
+		if v, ok := f.get("max"); ok {
+			if o.Maximum, err = p.float(v); err != nil {
+				return nil, err
+			}
+		}
+		if v, ok := f.get("closed"); ok {
+			if o.Closed, err = p.bool(v); err != nil {
+				return nil, err
+			}
+		}
+	case Triangle:
+		for _, key := range []string{"p1", "p2", "p3"} {
+			pt, err := p.requiredPoint(f, key)
+			if err != nil {
+				return nil, err
+			}
+			o.Points = append(o.Points, pt)
+		}
+	case Group:
+		if v, ok := f.get("children"); ok {
+			if o.Children, err = p.objects(v); err != nil {
+				return nil, err
+			}
+		}
+	case "obj":
+		v, ok := f.get("file")
+		if !ok {
+			return nil, p.errorf(f.node, "obj object has no file")
+		}
+		name, err := p.str(v)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.includeOBJ(o, v, name); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, p.errorf(typeNode, "unknown object type %q", o.Type)
+	}
+	return o, p.checkUnused(f)
+}
+
+// includeOBJ turns o into a group of triangles read from an OBJ file. Triangles
+// share the material of the including object.
+func (p *parser) includeOBJ(o *Object, ref *yaml.Node, name string) error {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(p.dir, path)
+	}
+	handle, err := os.Open(path)
+	if err != nil {
+		return p.errorf(ref, "can't include obj file: %v", err)
+	}
+	defer handle.Close()
+	children, err := ParseOBJ(handle, path)
+	if err != nil {
+		return err
+	}
+	setMaterial(children, o.Material)
+	o.Type = Group
+	o.Children = children
+	return nil
+}
+
+func setMaterial(objects []*Object, m *Material) {
+	for _, o := range objects {
+		o.Material = m
+		setMaterial(o.Children, m)
+	}
+}
+
+func (p *parser) material(n *yaml.Node) (*Material, error) {
+	f, err := p.mapping(n)
+	if err != nil {
+		return nil, err
+	}
+	m := DefaultMaterial()
+	if v, ok := f.get("color"); ok {
+		if m.Color, err = p.color(v); err != nil {
+			return nil, err
+		}
+	}
+	if v, ok := f.get("pattern"); ok {
+		if m.Pattern, err = p.pattern(v); err != nil {
+			return nil, err
+		}
+	}
+	properties := []struct {
+		key   string
+		value *float64
+	}{
+		{"ambient", &m.Ambient},
+		{"diffuse", &m.Diffuse},
+		{"specular", &m.Specular},
+		{"shininess", &m.Shininess},
+		{"reflective", &m.Reflective},
+		{"transparency", &m.Transparency},
+		{"refractive-index", &m.RefractiveIndex},
+	}
+	for _, prop := range properties {
+		if v, ok := f.get(prop.key); ok {
+			if *prop.value, err = p.float(v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, p.checkUnused(f)
+}
+
+func (p *parser) pattern(n *yaml.Node) (*Pattern, error) {
+	f, err := p.mapping(n)
+	if err != nil {
+		return nil, err
+	}
+	pat := &Pattern{Transform: matrix.Identity(4)}
+	typeNode, ok := f.get("type")
+	if !ok {
+		return nil, p.errorf(f.node, "pattern has no type")
+	}
+	if pat.Type, err = p.str(typeNode); err != nil {
+		return nil, err
+	}
+	switch pat.Type {
+	case Stripes, Gradient, Ring, Checkers:
+	default:
+		return nil, p.errorf(typeNode, "unknown pattern type %q", pat.Type)
+	}
+	colorsNode, ok := f.get("colors")
+	if !ok {
+		return nil, p.errorf(f.node, "pattern has no colors")
+	}
+	if err := p.each(colorsNode, func(item *yaml.Node) error {
+		c, err := p.color(item)
+		pat.Colors = append(pat.Colors, c)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if len(pat.Colors) != 2 {
+		return nil, p.errorf(colorsNode, "pattern needs exactly 2 colors, got %d", len(pat.Colors))
+	}
+	if v, ok := f.get("transform"); ok {
+		if pat.Transform, err = p.transform(v); err != nil {
+			return nil, err
+		}
+	}
+	return pat, p.checkUnused(f)
+}
+
+// transform multiplies a list of operations into a single matrix, the first
+// operation in the list is applied first. Items of the list can also be names of
+// defined lists, which are spliced in place.
+func (p *parser) transform(n *yaml.Node) (*matrix.Matrix, error) {
+	var steps []*matrix.Matrix
+	if err := p.transformSteps(n, &steps); err != nil {
+		return nil, err
+	}
+	return matrix.Chain(steps...)
+}
+
+func (p *parser) transformSteps(n *yaml.Node, steps *[]*matrix.Matrix) error {
+	if n.Kind == yaml.ScalarNode {
+		name := n.Value
+		d, err := p.define(n, name)
+		if err != nil {
+			return err
+		}
+		p.expanding[name] = true
+		defer delete(p.expanding, name)
+		return p.transformSteps(d, steps)
+	}
+	if n.Kind == yaml.AliasNode {
+		return p.transformSteps(n.Alias, steps)
+	}
+	if n.Kind != yaml.SequenceNode {
+		return p.errorf(n, "expected a list of transformations")
+	}
+	if p.isOperation(n) {
+		m, err := p.operation(n)
+		if err != nil {
+			return err
+		}
+		*steps = append(*steps, m)
+		return nil
+	}
+	for _, item := range n.Content {
+		if err := p.transformSteps(item, steps); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isOperation tells a single operation like [translate, 1, 2, 3] apart from a
+// list of names of defined transformations like [standard, large]
+func (p *parser) isOperation(n *yaml.Node) bool {
+	if len(n.Content) < 2 || n.Content[0].Kind != yaml.ScalarNode || n.Content[0].Tag != "!!str" {
+		return false
+	}
+	if _, defined := p.defines[n.Content[0].Value]; defined {
+		return false
+	}
+	for _, arg := range n.Content[1:] {
+		if arg.Kind == yaml.SequenceNode {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) operation(n *yaml.Node) (*matrix.Matrix, error) {
+	op := n.Content[0].Value
+	var args []float64
+	for _, item := range n.Content[1:] {
+		f, err := p.float(item)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, f)
+	}
+	argCount := func(counts ...int) error {
+		for _, c := range counts {
+			if len(args) == c {
+				return nil
+			}
+		}
+		return p.errorf(n, "%s takes %v arguments, got %d", op, strings.Trim(fmt.Sprint(counts), "[]"), len(args))
+	}
+
+	switch op {
+	case "translate":
+		if err := argCount(3); err != nil {
+			return nil, err
+		}
+		return matrix.Translation(args[0], args[1], args[2]), nil
+	case "scale":
+		if err := argCount(1, 3); err != nil {
+			return nil, err
+		}
+		if len(args) == 1 {
+			return matrix.Scaling(args[0], args[0], args[0]), nil
+		}
+		return matrix.Scaling(args[0], args[1], args[2]), nil
+	case "rotate-x", "rotate-y", "rotate-z":
+		if err := argCount(1); err != nil {
+			return nil, err
+		}
+		rotations := map[string]func(float64) *matrix.Matrix{
+			"rotate-x": matrix.RotationX,
+			"rotate-y": matrix.RotationY,
+			"rotate-z": matrix.RotationZ,
+		}
+		return rotations[op](degrees(args[0])), nil
+	case "shear":
+		if err := argCount(6); err != nil {
+			return nil, err
+		}
+		return matrix.Shearing(args[0], args[1], args[2], args[3], args[4], args[5]), nil
+	default:
+		return nil, p.errorf(n.Content[0], "unknown transformation %q", op)
+	}
+}
+
+// color parses either a list of three linear components or an sRGB hex string
+// like "#ff8800", which is converted to linear space
+func (p *parser) color(n *yaml.Node) (*color.Color, error) {
+	n, err := p.deref(n)
+	if err != nil {
+		return nil, err
+	}
+	if n.Kind == yaml.ScalarNode {
+		c, err := color.ParseHex(n.Value)
+		if err != nil {
+			return nil, p.errorf(n, "%v", err)
+		}
+		return color.SRGBToLinear(c), nil
+	}
+	t, err := p.triple(n)
+	if err != nil {
+		return nil, err
+	}
+	return color.NewColor(t[0], t[1], t[2]), nil
+}
+
+func (p *parser) vector(n *yaml.Node) (*vector.Vector, error) {
+	t, err := p.triple(n)
+	if err != nil {
+		return nil, err
+	}
+	return vector.NewVector(t[0], t[1], t[2]), nil
+}
+
+func (p *parser) point(n *yaml.Node) (*vector.Vector, error) {
+	t, err := p.triple(n)
+	if err != nil {
+		return nil, err
+	}
+	return vector.NewPoint(t[0], t[1], t[2]), nil
+}
+
+func (p *parser) triple(n *yaml.Node) ([3]float64, error) {
+	var result [3]float64
+	n, err := p.deref(n)
+	if err != nil {
+		return result, err
+	}
+	if n.Kind != yaml.SequenceNode || len(n.Content) != 3 {
+		return result, p.errorf(n, "expected a list of 3 numbers")
+	}
+	for i, item := range n.Content {
+		if result[i], err = p.float(item); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (p *parser) each(n *yaml.Node, f func(item *yaml.Node) error) error {
+	n, err := p.deref(n)
+	if err != nil {
+		return err
+	}
+	if n.Kind != yaml.SequenceNode {
+		return p.errorf(n, "expected a list")
+	}
+	for _, item := range n.Content {
+		if err := f(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *parser) requiredPoint(f *fields, key string) (*vector.Vector, error) {
+	v, ok := f.get(key)
+	if !ok {
+		return nil, p.errorf(f.node, "missing %q", key)
+	}
+	return p.point(v)
+}
+
+func (p *parser) requiredFloat(f *fields, key string) (float64, error) {
+	v, ok := f.get(key)
+	if !ok {
+		return 0, p.errorf(f.node, "missing %q", key)
+	}
+	return p.float(v)
+}
+
+func (p *parser) requiredInt(f *fields, key string) (int, error) {
+	v, ok := f.get(key)
+	if !ok {
+		return 0, p.errorf(f.node, "missing %q", key)
+	}
+	return p.int(v)
+}
+
+func (p *parser) float(n *yaml.Node) (float64, error) {
+	n, err := p.deref(n)
+	if err != nil {
+		return 0, err
+	}
+	var f float64
+	if n.Kind != yaml.ScalarNode || (n.Tag != "!!float" && n.Tag != "!!int") || n.Decode(&f) != nil {
+		return 0, p.errorf(n, "expected a number")
+	}
+	return f, nil
+}
+
+func (p *parser) int(n *yaml.Node) (int, error) {
+	n, err := p.deref(n)
+	if err != nil {
+		return 0, err
+	}
+	var i int
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!int" || n.Decode(&i) != nil {
+		return 0, p.errorf(n, "expected an integer")
+	}
+	return i, nil
+}
+
+func (p *parser) bool(n *yaml.Node) (bool, error) {
+	n, err := p.deref(n)
+	if err != nil {
+		return false, err
+	}
+	var b bool
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!bool" || n.Decode(&b) != nil {
+		return false, p.errorf(n, "expected true or false")
+	}
+	return b, nil
+}
+
+func (p *parser) str(n *yaml.Node) (string, error) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.ScalarNode || n.Tag != "!!str" {
+		return "", p.errorf(n, "expected a string")
+	}
+	return n.Value, nil
+}
+
+func degrees(d float64) float64 {
+	return d * math.Pi / 180
+}
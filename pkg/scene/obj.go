@@ -0,0 +1,94 @@
+package scene
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+)
+
+// ParseOBJ reads vertices and faces of a Wavefront OBJ file into triangles.
+// Polygons with more than three vertices are split into a fan of triangles and
+// named groups ("g" statements) become groups of their own. Statements other than
+// "v", "f" and "g" are ignored. File is used in error messages.
+func ParseOBJ(r io.Reader, file string) ([]*Object, error) {
+	var vertices []*vector.Vector
+	var result []*Object
+	current := &result
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		errorf := func(format string, args ...interface{}) error {
+			return &Error{File: file, Line: line, Column: 1, Msg: fmt.Sprintf(format, args...)}
+		}
+		f := strings.Fields(scanner.Text())
+		if len(f) == 0 {
+			continue
+		}
+
+		switch f[0] {
+		case "v":
+			if len(f) < 4 {
+				return nil, errorf("vertex needs 3 coordinates")
+			}
+			var xyz [3]float64
+			for i := range xyz {
+				c, err := strconv.ParseFloat(f[i+1], 64)
+				if err != nil {
+					return nil, errorf("invalid vertex coordinate %q", f[i+1])
+				}
+				xyz[i] = c
+			}
+			vertices = append(vertices, vector.NewPoint(xyz[0], xyz[1], xyz[2]))
+		case "f":
+			if len(f) < 4 {
+				return nil, errorf("face needs at least 3 vertices")
+			}
+			var face []*vector.Vector
+			for _, ref := range f[1:] {
+				idx, err := strconv.Atoi(strings.SplitN(ref, "/", 2)[0])
+				if err != nil {
+					return nil, errorf("invalid vertex reference %q", ref)
+				}
+				// Negative indices count back from the last vertex read so far
+				if idx < 0 {
+					idx = len(vertices) + idx + 1
+				}
+				if idx < 1 || idx > len(vertices) {
+					return nil, errorf("vertex %s is not defined", ref)
+				}
+				face = append(face, vertices[idx-1])
+			}
+			for i := 1; i < len(face)-1; i++ {
+				*current = append(*current, &Object{
+					Type:        Triangle,
+					Transform:   matrix.Identity(4),
+					Material:    DefaultMaterial(),
+					CastsShadow: true,
+					Points:      []*vector.Vector{face[0], face[i], face[i+1]},
+					File:        file,
+					Line:        line,
+				})
+			}
+		case "g":
+			g := &Object{
+				Type:        Group,
+				Transform:   matrix.Identity(4),
+				Material:    DefaultMaterial(),
+				CastsShadow: true,
+				File:        file,
+				Line:        line,
+			}
+			result = append(result, g)
+			current = &g.Children
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
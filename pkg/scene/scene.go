@@ -0,0 +1,112 @@
+// Package scene loads scene description files written in YAML (or JSON, which is
+// a subset of YAML) into a resolved Scene: every define is expanded, every list of
+// transformations is multiplied into a single matrix, every color is converted to
+// linear space and every OBJ include is read into a group of triangles.
+package scene
+
+import (
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+)
+
+// Shape types supported by the scene format
+const (
+	Sphere   = "sphere"
+	Plane    = "plane"
+	Cube     = "cube"
+	Cylinder = "cylinder"
+	Cone     = "cone"
+	Triangle = "triangle"
+	Group    = "group"
+)
+
+// Pattern types supported by the scene format
+const (
+	Stripes  = "stripes"
+	Gradient = "gradient"
+	Ring     = "ring"
+	Checkers = "checkers"
+)
+
+// Scene is everything needed to render an image: a camera, lights and objects
+type Scene struct {
+	Camera  *Camera
+	Lights  []*Light
+	Objects []*Object
+	// Background is the color of rays that don't hit anything
+	Background *color.Color
+}
+
+// Camera describes where the image is taken from and how big it is
+type Camera struct {
+	Width, Height int
+	// FieldOfView is the horizontal angle of view in radians
+	FieldOfView float64
+	From, To    *vector.Vector
+	Up          *vector.Vector
+	// Transform is the view transformation computed from From, To and Up
+	Transform *matrix.Matrix
+}
+
+// Light is a point light source
+type Light struct {
+	Position  *vector.Vector
+	Intensity *color.Color
+}
+
+// Material describes how the surface of an object reacts to light, using the
+// Phong reflection model
+type Material struct {
+	Color           *color.Color
+	Pattern         *Pattern
+	Ambient         float64
+	Diffuse         float64
+	Specular        float64
+	Shininess       float64
+	Reflective      float64
+	Transparency    float64
+	RefractiveIndex float64
+}
+
+// DefaultMaterial returns the material objects get when the scene doesn't specify
+// one, every property can be overridden separately
+func DefaultMaterial() *Material {
+	return &Material{
+		Color:           color.NewColor(1, 1, 1),
+		Ambient:         0.1,
+		Diffuse:         0.9,
+		Specular:        0.9,
+		Shininess:       200,
+		RefractiveIndex: 1,
+	}
+}
+
+// Pattern replaces the solid color of a material with colors that change across
+// the surface
+type Pattern struct {
+	Type      string
+	Colors    []*color.Color
+	Transform *matrix.Matrix
+}
+
+// Object is a shape or a group of shapes placed in the scene
+type Object struct {
+	Type string
+	// Transform places the object relative to its parent group, or to the world
+	// for top level objects
+	Transform   *matrix.Matrix
+	Material    *Material
+	CastsShadow bool
+	// Minimum, Maximum and Closed truncate cylinders and cones along the Y axis
+	Minimum, Maximum float64
+	Closed           bool
+	// Points are the three vertices of a triangle
+	Points []*vector.Vector
+	// Children are the objects of a group
+	Children []*Object
+	// File and Line tell where the object was defined, to make it possible to
+	// report problems found after loading
+	File string
+	Line int
+}
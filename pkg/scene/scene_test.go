@@ -0,0 +1,154 @@
+package scene
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	s, err := Load("testdata/scene.yml")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 100, s.Camera.Width)
+	assert.Equal(t, 50, s.Camera.Height)
+	assert.True(t, util.FloatEquals(s.Camera.FieldOfView, math.Pi/4))
+	assert.True(t, matrix.IsEqual(s.Camera.Transform, matrix.Translation(0, 0, -8)))
+	assert.True(t, color.Equals(s.Background, color.NewColor(0.1, 0.1, 0.1)))
+
+	assert.Len(t, s.Lights, 2)
+	assert.True(t, vector.Equals(s.Lights[0].Position, vector.NewPoint(-10, 10, -10)))
+	assert.True(t, color.Equals(s.Lights[1].Intensity, color.SRGBToLinear(color.NewColor(128.0/255, 128.0/255, 128.0/255))))
+
+	assert.Len(t, s.Objects, 4)
+	plane := s.Objects[0]
+	assert.Equal(t, Plane, plane.Type)
+	assert.False(t, plane.CastsShadow)
+	assert.Equal(t, Checkers, plane.Material.Pattern.Type)
+	assert.True(t, matrix.IsEqual(plane.Material.Pattern.Transform, matrix.RotationY(math.Pi/2)))
+	assert.Equal(t, 34, plane.Line)
+
+	sphere := s.Objects[1]
+	assert.Equal(t, Sphere, sphere.Type)
+	assert.True(t, sphere.CastsShadow)
+	assert.True(t, color.Equals(sphere.Material.Color, color.NewColor(0, 0, 1)))
+	assert.True(t, util.FloatEquals(sphere.Material.Diffuse, 0.7))
+	assert.True(t, util.FloatEquals(sphere.Material.Specular, 0.9))
+	want, _ := matrix.Chain(matrix.Translation(1, -1, 1), matrix.Scaling(0.5, 0.5, 0.5), matrix.Scaling(3.5, 3.5, 3.5))
+	assert.True(t, matrix.IsEqual(sphere.Transform, want))
+
+	group := s.Objects[2]
+	assert.Equal(t, Group, group.Type)
+	assert.True(t, matrix.IsEqual(group.Transform, matrix.Scaling(2, 2, 2)))
+	assert.Len(t, group.Children, 2)
+	assert.Equal(t, Cylinder, group.Children[0].Type)
+	assert.True(t, group.Children[0].Closed)
+	assert.Equal(t, 1.0, group.Children[0].Maximum)
+	assert.True(t, vector.Equals(group.Children[1].Points[0], vector.NewPoint(0, 1, 0)))
+
+	obj := s.Objects[3]
+	assert.Equal(t, Group, obj.Type)
+	assert.Len(t, obj.Children, 3)
+	assert.True(t, util.FloatEquals(obj.Children[2].Children[0].Material.Reflective, 0.5))
+}
+
+func TestParseJSON(t *testing.T) {
+	s, err := Parse([]byte(`{
+		"camera": {"width": 10, "height": 10, "field-of-view": 90, "from": [0, 0, -5], "to": [0, 0, 0]},
+		"objects": [{"type": "sphere", "transform": [["translate", 0, 1, 0]]}]
+	}`), "scene.json")
+	assert.Nil(t, err)
+	assert.Len(t, s.Objects, 1)
+	assert.True(t, matrix.IsEqual(s.Objects[0].Transform, matrix.Translation(0, 1, 0)))
+	assert.True(t, color.Equals(s.Background, color.NewColor(0, 0, 0)))
+}
+
+func TestParseErrors(t *testing.T) {
+	camera := "camera: {width: 10, height: 10, field-of-view: 90, from: [0, 0, -5], to: [0, 0, 0]}\n"
+
+	tests := map[string]struct {
+		input string
+		line  int
+		col   int
+		msg   string
+	}{
+		"no camera":         {input: "lights: []\n", line: 1, col: 1, msg: "scene has no camera"},
+		"unknown top key":   {input: camera + "objcts: []\n", line: 2, col: 1, msg: `unknown key "objcts"`},
+		"unknown type":      {input: camera + "objects:\n  - type: teapot\n", line: 3, col: 11, msg: `unknown object type "teapot"`},
+		"bad number":        {input: camera + "objects:\n  - type: sphere\n    material: {diffuse: lots}\n", line: 4, col: 25, msg: "expected a number"},
+		"bad transform":     {input: camera + "objects:\n  - type: sphere\n    transform: [[spin, 1]]\n", line: 4, col: 18, msg: `unknown transformation "spin"`},
+		"wrong arg count":   {input: camera + "objects:\n  - type: sphere\n    transform: [[translate, 1]]\n", line: 4, col: 17, msg: "translate takes 3 arguments, got 1"},
+		"undefined extend":  {input: camera + "objects:\n  - extend: nothing\n", line: 3, col: 13, msg: `undefined name "nothing"`},
+		"undefined name":    {input: camera + "objects:\n  - type: sphere\n    transform: [missing]\n", line: 4, col: 17, msg: `undefined name "missing"`},
+		"bad hex":           {input: camera + "background: '#zz'\n", line: 2, col: 13, msg: `invalid hex color "#zz"`},
+		"short vector":      {input: camera + "lights:\n  - at: [1, 2]\n", line: 3, col: 9, msg: "expected a list of 3 numbers"},
+		"missing key":       {input: camera + "lights:\n  - intensity: [1, 1, 1]\n", line: 3, col: 5, msg: `missing "at"`},
+		"unknown in define": {input: "define:\n  m: {colour: [1, 0, 0]}\n" + camera + "objects:\n  - {type: sphere, material: m}\n", line: 2, col: 7, msg: `unknown key "colour"`},
+		"self reference":    {input: "define:\n  a: {extend: a}\n" + camera + "objects:\n  - {type: sphere, material: a}\n", line: 2, col: 15, msg: `define "a" refers to itself`},
+		"self child":        {input: "define:\n  g: {type: group, children: [g]}\n" + camera + "objects: [g]\n", line: 2, col: 31, msg: `define "g" refers to itself`},
+		"indirect child":    {input: "define:\n  a: {type: group, children: [b]}\n  b: {type: group, children: [{extend: a}]}\n" + camera + "objects: [a]\n", line: 3, col: 40, msg: `define "a" refers to itself`},
+		"missing obj":       {input: camera + "objects:\n  - {type: obj, file: nothing.obj}\n", line: 3, col: 23, msg: "can't include obj file"},
+		"pattern colors":    {input: camera + "objects:\n  - type: plane\n    material: {pattern: {type: ring, colors: [[1, 1, 1]]}}\n", line: 4, col: 46, msg: "pattern needs exactly 2 colors, got 1"},
+		"empty file":        {input: "", line: 1, col: 1, msg: "scene file is empty"},
+	}
+
+	for name, tc := range tests {
+		_, err := Parse([]byte(tc.input), "test.yml")
+		var serr *Error
+		if !errors.As(err, &serr) {
+			t.Fatalf("%s: expected scene error, got %v", name, err)
+		}
+		assert.Equal(t, tc.line, serr.Line, name)
+		assert.Equal(t, tc.col, serr.Column, name)
+		assert.True(t, strings.Contains(serr.Msg, tc.msg), "%s: %q doesn't contain %q", name, serr.Msg, tc.msg)
+		assert.True(t, strings.HasPrefix(err.Error(), "test.yml:"), name)
+	}
+
+	_, err := Parse([]byte("camera: [unclosed\n"), "test.yml")
+	assert.NotNil(t, err)
+}
+
+func TestParseOBJ(t *testing.T) {
+	objects, err := ParseOBJ(strings.NewReader(`
+v -1 1 0
+v -1 0 0
+v 1 0 0
+v 1 1 0
+v 0 2 0
+vn 0 0 1
+f 1 2 3 4 5
+`), "fan.obj")
+	assert.Nil(t, err)
+	assert.Len(t, objects, 3)
+	for i, o := range objects {
+		assert.Equal(t, Triangle, o.Type)
+		assert.True(t, vector.Equals(o.Points[0], vector.NewPoint(-1, 1, 0)))
+		assert.Equal(t, 8, o.Line, i)
+	}
+	assert.True(t, vector.Equals(objects[2].Points[2], vector.NewPoint(0, 2, 0)))
+
+	tests := map[string]struct {
+		input string
+		line  int
+	}{
+		"bad vertex":       {input: "v 1 2 x\n", line: 1},
+		"short vertex":     {input: "v 1 2\n", line: 1},
+		"undefined vertex": {input: "v 1 2 3\n\nf 1 2 3\n", line: 3},
+		"bad reference":    {input: "v 1 2 3\nf a b c\n", line: 2},
+	}
+	for name, tc := range tests {
+		_, err := ParseOBJ(strings.NewReader(tc.input), "bad.obj")
+		var serr *Error
+		if !errors.As(err, &serr) {
+			t.Fatalf("%s: expected scene error, got %v", name, err)
+		}
+		assert.Equal(t, tc.line, serr.Line, name)
+	}
+}
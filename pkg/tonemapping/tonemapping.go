@@ -0,0 +1,122 @@
+package tonemapping
+
+import (
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+)
+
+// Operator maps a linear color with unbounded components to a color with
+// components in [0, 1] range that can be quantized by a canvas encoder
+type Operator interface {
+	Map(c *color.Color) *color.Color
+}
+
+// OperatorFunc is an adapter that allows to use an ordinary function as an Operator
+type OperatorFunc func(c *color.Color) *color.Color
+
+// Map calls f(c)
+func (f OperatorFunc) Map(c *color.Color) *color.Color {
+	return f(c)
+}
+
+// Clamp cuts every component to [0, 1] range. It's the same mapping that
+// color.ColorTo255Range does, so highlights brighter than 1 are blown out.
+var Clamp = perComponent(clamp)
+
+// Reinhard maps every component x to x / (1 + x), which compresses arbitrary
+// bright values into [0, 1) range but never reaches pure white
+var Reinhard = perComponent(func(x float64) float64 {
+	x = math.Max(x, 0)
+	return x / (1 + x)
+})
+
+// ACES approximates the ACES filmic curve using the fit by Krzysztof Narkowicz
+var ACES = perComponent(func(x float64) float64 {
+	x = math.Max(x, 0)
+	return clamp((x * (2.51*x + 0.03)) / (x*(2.43*x+0.59) + 0.14))
+})
+
+// ReinhardExtended is a Reinhard operator which maps White and anything brighter
+// to 1, so that the brightest parts of the image become pure white. White that
+// isn't positive, including the zero value, works the same way as Reinhard.
+type ReinhardExtended struct {
+	White float64
+}
+
+// Map applies the extended Reinhard curve to every component of the color
+func (r ReinhardExtended) Map(c *color.Color) *color.Color {
+	if r.White <= 0 {
+		return Reinhard.Map(c)
+	}
+	w2 := r.White * r.White
+	return perComponent(func(x float64) float64 {
+		x = math.Max(x, 0)
+		return clamp(x * (1 + x/w2) / (1 + x))
+	}).Map(c)
+}
+
+// Exposure scales the color by 2^Stops, so positive stops brighten the image and
+// negative stops darken it
+type Exposure struct {
+	Stops float64
+}
+
+// Map scales the color by the exposure factor
+func (e Exposure) Map(c *color.Color) *color.Color {
+	return color.Scale(c, math.Exp2(e.Stops))
+}
+
+// SRGB clamps linear components to [0, 1] range and encodes them with the sRGB
+// transfer function
+var SRGB = OperatorFunc(func(c *color.Color) *color.Color {
+	return color.LinearToSRGB(Clamp(c))
+})
+
+// Pipeline is a chain of operators applied one after another
+type Pipeline []Operator
+
+// Map passes color through every operator of the pipeline in order
+func (p Pipeline) Map(c *color.Color) *color.Color {
+	for _, op := range p {
+		c = op.Map(c)
+	}
+	return c
+}
+
+// NewPipeline creates the usual display pipeline: exposure adjustment by stops,
+// tone mapping curve op and sRGB encoding
+func NewPipeline(stops float64, op Operator) Pipeline {
+	return Pipeline{Exposure{stops}, op, SRGB}
+}
+
+// Apply returns a new canvas with op applied to every pixel of c. The result can
+// be saved with any canvas encoder, e.g. tonemapping.Apply(c, op).SaveToPPM(file).
+// Operators work on straight colors, so partially transparent pixels are
+// unpremultiplied before mapping and premultiplied again afterwards.
+func Apply(c *canvas.Canvas, op Operator) *canvas.Canvas {
+	result := canvas.NewCanvas(c.Width, c.Height)
+	for h, row := range c.Colors {
+		for w, col := range row {
+			alpha, _ := c.GetAlpha(w, h)
+			if alpha == 1 {
+				result.WritePixel(w, h, op.Map(col))
+				continue
+			}
+			mapped := op.Map(color.Unpremultiply(col, alpha))
+			result.WritePixelAlpha(w, h, color.Premultiply(mapped, alpha), alpha)
+		}
+	}
+	return result
+}
+
+func perComponent(f func(float64) float64) OperatorFunc {
+	return func(c *color.Color) *color.Color {
+		return color.NewColor(f(c.Red), f(c.Green), f(c.Blue))
+	}
+}
+
+func clamp(x float64) float64 {
+	return math.Min(math.Max(x, 0), 1)
+}
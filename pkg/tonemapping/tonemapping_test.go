@@ -0,0 +1,71 @@
+package tonemapping
+
+import (
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/canvas"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperators(t *testing.T) {
+	tests := map[string]struct {
+		op    Operator
+		input *color.Color
+		want  *color.Color
+	}{
+		"clamp":                  {op: Clamp, input: color.NewColor(1.5, 0.5, -0.5), want: color.NewColor(1, 0.5, 0)},
+		"reinhard":               {op: Reinhard, input: color.NewColor(1, 3, 0), want: color.NewColor(0.5, 0.75, 0)},
+		"reinhard negative":      {op: Reinhard, input: color.NewColor(-1, 0, 0), want: color.NewColor(0, 0, 0)},
+		"reinhard extended":      {op: ReinhardExtended{White: 4}, input: color.NewColor(4, 8, 1), want: color.NewColor(1, 1, 0.53125)},
+		"reinhard extended zero": {op: ReinhardExtended{}, input: color.NewColor(1, 3, 0), want: color.NewColor(0.5, 0.75, 0)},
+		"aces black":             {op: ACES, input: color.NewColor(0, 0, 0), want: color.NewColor(0, 0, 0)},
+		"aces saturates":         {op: ACES, input: color.NewColor(100, 100, 100), want: color.NewColor(1, 1, 1)},
+		"aces mid gray":          {op: ACES, input: color.NewColor(0.18, 0.18, 0.18), want: color.NewColor(0.26690, 0.26690, 0.26690)},
+		"exposure up":            {op: Exposure{Stops: 1}, input: color.NewColor(0.25, 0.5, 1), want: color.NewColor(0.5, 1, 2)},
+		"exposure down":          {op: Exposure{Stops: -2}, input: color.NewColor(1, 2, 4), want: color.NewColor(0.25, 0.5, 1)},
+		"srgb":                   {op: SRGB, input: color.NewColor(0, 0.5, 1), want: color.NewColor(0, 0.73536, 1)},
+		"srgb linear segment":    {op: SRGB, input: color.NewColor(0.001, 0, 0), want: color.NewColor(0.01292, 0, 0)},
+		"pipeline clamp":         {op: NewPipeline(0, Clamp), input: color.NewColor(2, 0.5, 0), want: color.NewColor(1, 0.73536, 0)},
+		"pipeline with stops":    {op: NewPipeline(-1, Clamp), input: color.NewColor(2, 1, 0), want: color.NewColor(1, 0.73536, 0)},
+		"empty pipeline":         {op: Pipeline{}, input: color.NewColor(2, 1, 0), want: color.NewColor(2, 1, 0)},
+		"operator func adapter":  {op: OperatorFunc(func(c *color.Color) *color.Color { return c }), input: color.NewColor(3, 2, 1), want: color.NewColor(3, 2, 1)},
+	}
+
+	for name, tc := range tests {
+		got := tc.op.Map(tc.input)
+		if !color.Equals(got, tc.want) {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	c := canvas.NewCanvas(2, 1)
+	c.WritePixel(0, 0, color.NewColor(1, 3, 0))
+	c.WritePixel(1, 0, color.NewColor(0, 1, 1))
+
+	got := Apply(c, Reinhard)
+	assert.Equal(t, 2, got.Width)
+	assert.Equal(t, 1, got.Height)
+	p, _ := got.GetPixel(0, 0)
+	assert.True(t, color.Equals(p, color.NewColor(0.5, 0.75, 0)))
+	p, _ = got.GetPixel(1, 0)
+	assert.True(t, color.Equals(p, color.NewColor(0, 0.5, 0.5)))
+
+	// Source canvas is left untouched
+	p, _ = c.GetPixel(0, 0)
+	assert.True(t, color.Equals(p, color.NewColor(1, 3, 0)))
+}
+
+func TestApplyAlpha(t *testing.T) {
+	c := canvas.NewCanvas(1, 1)
+	c.WritePixelAlpha(0, 0, color.NewColor(1.5, 0, 0), 0.5)
+
+	// Operators work on straight colors, so the pixel is 3 before Reinhard
+	got := Apply(c, Reinhard)
+	p, _ := got.GetPixel(0, 0)
+	assert.True(t, color.Equals(p, color.NewColor(0.375, 0, 0)))
+	a, _ := got.GetAlpha(0, 0)
+	assert.Equal(t, 0.5, a)
+}
@@ -0,0 +1,116 @@
+package tracer
+
+import (
+	"math"
+	"sort"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+)
+
+// leafSize is the largest number of shapes kept in a single node of the bounding
+// volume hierarchy
+const leafSize = 4
+
+// box is an axis aligned bounding box
+type box struct {
+	min, max vector.Point
+}
+
+func emptyBox() box {
+	inf := math.Inf(1)
+	return box{vector.Point{X: inf, Y: inf, Z: inf}, vector.Point{X: -inf, Y: -inf, Z: -inf}}
+}
+
+// add returns b grown to contain p
+func (b box) add(p vector.Point) box {
+	return box{
+		vector.Point{X: math.Min(b.min.X, p.X), Y: math.Min(b.min.Y, p.Y), Z: math.Min(b.min.Z, p.Z)},
+		vector.Point{X: math.Max(b.max.X, p.X), Y: math.Max(b.max.Y, p.Y), Z: math.Max(b.max.Z, p.Z)},
+	}
+}
+
+// merge returns a box containing both b and o
+func (b box) merge(o box) box {
+	return b.add(o.min).add(o.max)
+}
+
+// transform returns a box containing b transformed by m
+func (b box) transform(m *matrix.Matrix) box {
+	result := emptyBox()
+	for _, x := range [2]float64{b.min.X, b.max.X} {
+		for _, y := range [2]float64{b.min.Y, b.max.Y} {
+			for _, z := range [2]float64{b.min.Z, b.max.Z} {
+				p, _ := matrix.TransformPoint(m, vector.Point{X: x, Y: y, Z: z})
+				result = result.add(p)
+			}
+		}
+	}
+	return result
+}
+
+func (b box) center() vector.Point {
+	return vector.Point{X: (b.min.X + b.max.X) / 2, Y: (b.min.Y + b.max.Y) / 2, Z: (b.min.Z + b.max.Z) / 2}
+}
+
+// hits checks if r passes through b anywhere in front of its origin. Shapes
+// entirely behind the origin can be skipped, because a ray enters and leaves
+// them before the origin, which doesn't change what it's inside of.
+func (b box) hits(r localRay) bool {
+	xmin, xmax := slab(r.origin.X, r.direction.X, b.min.X, b.max.X)
+	ymin, ymax := slab(r.origin.Y, r.direction.Y, b.min.Y, b.max.Y)
+	zmin, zmax := slab(r.origin.Z, r.direction.Z, b.min.Z, b.max.Z)
+	tmin := math.Max(xmin, math.Max(ymin, zmin))
+	tmax := math.Min(xmax, math.Min(ymax, zmax))
+	return tmin <= tmax && tmax >= 0
+}
+
+// bvh is a bounding volume hierarchy, which lets rays skip whole groups of
+// shapes they can't hit
+type bvh struct {
+	bounds      box
+	left, right *bvh
+	shapes      []*shape
+}
+
+// newBVH builds a hierarchy over bounded shapes by splitting them in half along
+// the longest axis of their centers
+func newBVH(shapes []*shape) *bvh {
+	node := &bvh{bounds: emptyBox()}
+	centers := emptyBox()
+	for _, s := range shapes {
+		node.bounds = node.bounds.merge(s.bounds)
+		centers = centers.add(s.bounds.center())
+	}
+	if len(shapes) <= leafSize {
+		node.shapes = shapes
+		return node
+	}
+
+	size := centers.max.Subtract(centers.min)
+	axis := func(p vector.Point) float64 { return p.X }
+	if size.Y > size.X && size.Y >= size.Z {
+		axis = func(p vector.Point) float64 { return p.Y }
+	} else if size.Z > size.X && size.Z > size.Y {
+		axis = func(p vector.Point) float64 { return p.Z }
+	}
+	sorted := append([]*shape(nil), shapes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return axis(sorted[i].bounds.center()) < axis(sorted[j].bounds.center())
+	})
+	node.left = newBVH(sorted[:len(sorted)/2])
+	node.right = newBVH(sorted[len(sorted)/2:])
+	return node
+}
+
+// intersect appends every hit of r with shapes in the hierarchy
+func (b *bvh) intersect(r localRay, hits []hit) []hit {
+	if !b.bounds.hits(r) {
+		return hits
+	}
+	if b.left != nil {
+		hits = b.left.intersect(r, hits)
+		return b.right.intersect(r, hits)
+	}
+	return intersectShapes(b.shapes, r, hits)
+}
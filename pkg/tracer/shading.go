@@ -0,0 +1,254 @@
+package tracer
+
+import (
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/render"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+)
+
+var black = color.NewColor(0, 0, 0)
+
+// surface describes the point where a ray hit a shape
+type surface struct {
+	t     float64
+	shape *shape
+	point vector.Point
+	// overPoint and underPoint are moved slightly above and below the surface
+	// to start reflected and refracted rays from
+	overPoint, underPoint vector.Point
+	eye, reflect          vector.Vec3
+	// normal always points to the side the ray came from
+	normal vector.Normal
+	// n1 and n2 are the refractive indices on the side the ray comes from and
+	// the side it enters
+	n1, n2 float64
+}
+
+// colorAt returns the color seen along r, which reflects or refracts at most
+// depth more times. It returns the background if r hits nothing.
+func (w *World) colorAt(r localRay, depth int, aovs render.AOVs) *color.Color {
+	hits := w.intersect(r)
+	i := firstHit(hits)
+	if i < 0 {
+		return w.Background
+	}
+	s := newSurface(r, hits, i)
+	return w.shade(s, depth, aovs)
+}
+
+// secondaryColorAt works like colorAt for reflected and refracted rays, which
+// see black instead of a transparent background
+func (w *World) secondaryColorAt(r localRay, depth int) *color.Color {
+	if c := w.colorAt(r, depth, nil); c != nil {
+		return c
+	}
+	return black
+}
+
+func newSurface(r localRay, hits []hit, i int) surface {
+	h := hits[i]
+	s := surface{t: h.t, shape: h.s, point: r.position(h.t), eye: r.direction.Negate()}
+	if n, err := s.eye.Normal(); err == nil {
+		s.eye = n.Vec3()
+	}
+	s.normal = h.s.normalAt(s.point)
+	if s.normal.Dot(s.eye) < 0 {
+		s.normal = s.normal.Negate()
+	}
+	offset := s.normal.Vec3().Multiply(epsilon)
+	s.overPoint = s.point.Add(offset)
+	s.underPoint = s.point.Add(offset.Negate())
+	s.reflect = vec3(s.eye.Negate().Vector().Reflect(s.normal.Vector()))
+	s.n1, s.n2 = refractiveIndices(hits, i)
+	return s
+}
+
+// refractiveIndices goes through hits up to hit i keeping track of the shapes
+// the ray is inside of, to find the materials on both sides of the surface
+func refractiveIndices(hits []hit, i int) (float64, float64) {
+	var containers []*shape
+	n1, n2 := 1.0, 1.0
+	for j, h := range hits[:i+1] {
+		if j == i && len(containers) > 0 {
+			n1 = containers[len(containers)-1].material.RefractiveIndex
+		}
+		found := false
+		for k, c := range containers {
+			if c == h.s {
+				containers = append(containers[:k], containers[k+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			containers = append(containers, h.s)
+		}
+		if j == i && len(containers) > 0 {
+			n2 = containers[len(containers)-1].material.RefractiveIndex
+		}
+	}
+	return n1, n2
+}
+
+// shade returns the color of surface s lit by every light, with reflections and
+// refractions, and stores values of passes in aovs unless it's nil
+func (w *World) shade(s surface, depth int, aovs render.AOVs) *color.Color {
+	m := s.shape.material
+	base := albedo(s.shape, s.overPoint)
+	result := black
+	shadowed := 0
+	for _, l := range w.lights {
+		inShadow := w.isShadowed(s.overPoint, l)
+		if inShadow {
+			shadowed++
+		}
+		result = color.Add(result, lighting(m, base, l, s.overPoint, s.eye, s.normal, inShadow))
+	}
+
+	reflected := w.reflectedColor(s, depth)
+	refracted := w.refractedColor(s, depth)
+	if m.Reflective > 0 && m.Transparency > 0 {
+		reflectance := schlick(s)
+		reflected = color.Scale(reflected, reflectance)
+		refracted = color.Scale(refracted, 1-reflectance)
+	}
+	result = color.Add(result, color.Add(reflected, refracted))
+
+	if aovs != nil {
+		aovs.SetValue(render.Depth, s.t)
+		aovs[render.Normal.Name] = color.NewColor(s.normal.X, s.normal.Y, s.normal.Z)
+		aovs[render.Albedo.Name] = base
+		// IDs start from 1, so that objects differ from the empty background
+		aovs.SetValue(render.ObjectID, float64(s.shape.object+1))
+		if len(w.lights) > 0 {
+			aovs.SetValue(render.Shadow, float64(shadowed)/float64(len(w.lights)))
+		}
+		aovs[render.Reflection.Name] = reflected
+	}
+	return result
+}
+
+// lighting computes the color of a point lit by l using the Phong reflection
+// model. Points in shadow only get the ambient part.
+func lighting(m *scene.Material, albedo *color.Color, l light, point vector.Point, eye vector.Vec3, normal vector.Normal, inShadow bool) *color.Color {
+	effective := color.Multiply(albedo, l.intensity)
+	ambient := color.Scale(effective, m.Ambient)
+	if inShadow {
+		return ambient
+	}
+	toLight, err := l.position.Subtract(point).Normal()
+	if err != nil {
+		return ambient
+	}
+	lightDotNormal := normal.Dot(toLight.Vec3())
+	if lightDotNormal < 0 {
+		return ambient
+	}
+	diffuse := color.Scale(effective, m.Diffuse*lightDotNormal)
+	reflect := toLight.Vector().Negate().Reflect(normal.Vector())
+	reflectDotEye := reflect.Dot(eye.Vector())
+	if reflectDotEye <= 0 {
+		return color.Add(ambient, diffuse)
+	}
+	specular := color.Scale(l.intensity, m.Specular*math.Pow(reflectDotEye, m.Shininess))
+	return color.Add(ambient, color.Add(diffuse, specular))
+}
+
+// isShadowed checks if anything casting shadows is between point and l
+func (w *World) isShadowed(point vector.Point, l light) bool {
+	toLight := l.position.Subtract(point)
+	distance := toLight.Magnitude()
+	direction, err := toLight.Normal()
+	if err != nil {
+		return false
+	}
+	for _, h := range w.intersect(localRay{point, direction.Vec3()}) {
+		if h.t >= 0 && h.t < distance && h.s.castsShadow {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *World) reflectedColor(s surface, depth int) *color.Color {
+	reflective := s.shape.material.Reflective
+	if depth <= 0 || reflective == 0 {
+		return black
+	}
+	c := w.secondaryColorAt(localRay{s.overPoint, s.reflect}, depth-1)
+	return color.Scale(c, reflective)
+}
+
+func (w *World) refractedColor(s surface, depth int) *color.Color {
+	transparency := s.shape.material.Transparency
+	if depth <= 0 || transparency == 0 {
+		return black
+	}
+	direction, ok := s.eye.Negate().Vector().Refract(s.normal.Vector(), s.n1/s.n2)
+	if !ok {
+		// Total internal reflection
+		return black
+	}
+	c := w.secondaryColorAt(localRay{s.underPoint, vec3(direction)}, depth-1)
+	return color.Scale(c, transparency)
+}
+
+// schlick approximates the Fresnel equations, returning the part of light that
+// is reflected rather than refracted at surface s
+func schlick(s surface) float64 {
+	cos := s.normal.Dot(s.eye)
+	if s.n1 > s.n2 {
+		n := s.n1 / s.n2
+		sin2T := n * n * (1 - cos*cos)
+		if sin2T > 1 {
+			return 1
+		}
+		cos = math.Sqrt(1 - sin2T)
+	}
+	r0 := (s.n1 - s.n2) / (s.n1 + s.n2)
+	r0 *= r0
+	return r0 + (1-r0)*math.Pow(1-cos, 5)
+}
+
+// albedo returns the color of the material of sh at world space point p, before
+// it's lit
+func albedo(sh *shape, p vector.Point) *color.Color {
+	pattern := sh.material.Pattern
+	if pattern == nil {
+		return sh.material.Color
+	}
+	local, _ := matrix.TransformPoint(sh.inverse, p)
+	local, _ = matrix.TransformPoint(sh.patternInverse, local)
+	return patternColor(pattern, local)
+}
+
+// patternColor returns the color of pattern at pattern space point p
+func patternColor(pattern *scene.Pattern, p vector.Point) *color.Color {
+	a, b := pattern.Colors[0], pattern.Colors[1]
+	pick := func(n float64) *color.Color {
+		if math.Mod(n, 2) == 0 {
+			return a
+		}
+		return b
+	}
+	switch pattern.Type {
+	case scene.Stripes:
+		return pick(math.Floor(p.X))
+	case scene.Gradient:
+		return color.Lerp(a, b, p.X-math.Floor(p.X))
+	case scene.Ring:
+		return pick(math.Floor(math.Hypot(p.X, p.Z)))
+	case scene.Checkers:
+		return pick(math.Floor(p.X) + math.Floor(p.Y) + math.Floor(p.Z))
+	}
+	return a
+}
+
+// vec3 drops W of a direction computed with the Vector methods
+func vec3(v vector.Vector) vector.Vec3 {
+	return vector.Vec3{X: v.X, Y: v.Y, Z: v.Z}
+}
@@ -0,0 +1,292 @@
+package tracer
+
+import (
+	"math"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+)
+
+// epsilon is the distance hit points are moved off surfaces by, so that rays
+// leaving a surface don't hit it again because of rounding errors
+const epsilon = 1e-5
+
+// localRay is a ray in the space of a single shape, or in world space, kept as
+// values to avoid allocating in the innermost loops
+type localRay struct {
+	origin    vector.Point
+	direction vector.Vec3
+}
+
+func (r localRay) position(t float64) vector.Point {
+	return r.origin.Add(r.direction.Multiply(t))
+}
+
+// transform applies m to r. Matrices of shapes are checked to be 4x4 when the
+// world is created, so it can't fail.
+func (r localRay) transform(m *matrix.Matrix) localRay {
+	origin, _ := matrix.TransformPoint(m, r.origin)
+	direction, _ := matrix.TransformVec3(m, r.direction)
+	return localRay{origin, direction}
+}
+
+// shape is a single primitive of the scene, with the transformations of every
+// group it's in already multiplied into its own
+type shape struct {
+	kind string
+	// object is the index of the top level scene object the shape comes from
+	object      int
+	material    *scene.Material
+	castsShadow bool
+	// inverse transforms world space to object space and normal transforms
+	// object space normals to world space
+	inverse, normal *matrix.Matrix
+	// patternInverse transforms object space to pattern space
+	patternInverse *matrix.Matrix
+
+	minimum, maximum float64
+	closed           bool
+
+	// p1 is the first vertex of a triangle, e1 and e2 go to the other two
+	p1             vector.Point
+	e1, e2         vector.Vec3
+	triangleNormal vector.Normal
+
+	// bounds encloses the shape in world space if bounded is set, which it
+	// isn't for planes and for cylinders and cones that aren't truncated
+	bounds  box
+	bounded bool
+}
+
+// intersect appends distances along world space ray r where it hits s
+func (s *shape) intersect(r localRay, ts []float64) []float64 {
+	r = r.transform(s.inverse)
+	switch s.kind {
+	case scene.Sphere:
+		return intersectSphere(r, ts)
+	case scene.Plane:
+		return intersectPlane(r, ts)
+	case scene.Cube:
+		return intersectCube(r, ts)
+	case scene.Cylinder:
+		return s.intersectCylinder(r, ts, false)
+	case scene.Cone:
+		return s.intersectCylinder(r, ts, true)
+	case scene.Triangle:
+		return s.intersectTriangle(r, ts)
+	}
+	return ts
+}
+
+// normalAt returns the world space normal of s at world space point p
+func (s *shape) normalAt(p vector.Point) vector.Normal {
+	local, _ := matrix.TransformPoint(s.inverse, p)
+	var n vector.Vec3
+	switch s.kind {
+	case scene.Sphere:
+		n = local.Subtract(vector.Point{})
+	case scene.Plane:
+		n = vector.Vec3{Y: 1}
+	case scene.Cube:
+		n = cubeNormal(local)
+	case scene.Cylinder:
+		n = s.cylinderNormal(local, false)
+	case scene.Cone:
+		n = s.cylinderNormal(local, true)
+	case scene.Triangle:
+		n = s.triangleNormal.Vec3()
+	}
+	world, _ := matrix.TransformVec3(s.normal, n)
+	result, err := world.Normal()
+	if err != nil {
+		// Degenerate transformations have no meaningful normal, any unit
+		// vector is better than propagating NaN into the image
+		return vector.Normal{Y: 1}
+	}
+	return result
+}
+
+// intersectSphere intersects a unit sphere at the origin
+func intersectSphere(r localRay, ts []float64) []float64 {
+	toRay := r.origin.Subtract(vector.Point{})
+	a := r.direction.Dot(r.direction)
+	b := 2 * r.direction.Dot(toRay)
+	c := toRay.Dot(toRay) - 1
+	discriminant := b*b - 4*a*c
+	if discriminant < 0 {
+		return ts
+	}
+	root := math.Sqrt(discriminant)
+	return append(ts, (-b-root)/(2*a), (-b+root)/(2*a))
+}
+
+// intersectPlane intersects the XZ plane
+func intersectPlane(r localRay, ts []float64) []float64 {
+	if math.Abs(r.direction.Y) < epsilon {
+		return ts
+	}
+	return append(ts, -r.origin.Y/r.direction.Y)
+}
+
+// intersectCube intersects an axis aligned cube from -1 to 1
+func intersectCube(r localRay, ts []float64) []float64 {
+	xmin, xmax := slab(r.origin.X, r.direction.X, -1, 1)
+	ymin, ymax := slab(r.origin.Y, r.direction.Y, -1, 1)
+	zmin, zmax := slab(r.origin.Z, r.direction.Z, -1, 1)
+	tmin := math.Max(xmin, math.Max(ymin, zmin))
+	tmax := math.Min(xmax, math.Min(ymax, zmax))
+	if tmin > tmax {
+		return ts
+	}
+	return append(ts, tmin, tmax)
+}
+
+// slab returns the range of distances where a ray is between min and max along
+// a single axis
+func slab(origin, direction, min, max float64) (float64, float64) {
+	if direction == 0 {
+		if origin < min || origin > max {
+			return math.Inf(1), math.Inf(-1)
+		}
+		return math.Inf(-1), math.Inf(1)
+	}
+	tmin := (min - origin) / direction
+	tmax := (max - origin) / direction
+	if tmin > tmax {
+		return tmax, tmin
+	}
+	return tmin, tmax
+}
+
+func cubeNormal(p vector.Point) vector.Vec3 {
+	x, y, z := math.Abs(p.X), math.Abs(p.Y), math.Abs(p.Z)
+	switch {
+	case x >= y && x >= z:
+		return vector.Vec3{X: p.X}
+	case y >= z:
+		return vector.Vec3{Y: p.Y}
+	}
+	return vector.Vec3{Z: p.Z}
+}
+
+// intersectCylinder intersects a unit cylinder, or a double cone if cone is
+// set, around the Y axis truncated to the range from minimum to maximum
+func (s *shape) intersectCylinder(r localRay, ts []float64, cone bool) []float64 {
+	d, o := r.direction, r.origin
+	a := d.X*d.X + d.Z*d.Z
+	b := 2 * (o.X*d.X + o.Z*d.Z)
+	c := o.X*o.X + o.Z*o.Z - 1
+	if cone {
+		a -= d.Y * d.Y
+		b -= 2 * o.Y * d.Y
+		c = o.X*o.X + o.Z*o.Z - o.Y*o.Y
+	}
+
+	switch {
+	case math.Abs(a) >= epsilon:
+		discriminant := b*b - 4*a*c
+		if discriminant >= 0 {
+			root := math.Sqrt(discriminant)
+			for _, t := range [2]float64{(-b - root) / (2 * a), (-b + root) / (2 * a)} {
+				if y := o.Y + t*d.Y; s.minimum < y && y < s.maximum {
+					ts = append(ts, t)
+				}
+			}
+		}
+	case cone && math.Abs(b) >= epsilon:
+		// The ray is parallel to one of the halves of the cone
+		t := -c / (2 * b)
+		if y := o.Y + t*d.Y; s.minimum < y && y < s.maximum {
+			ts = append(ts, t)
+		}
+	}
+	return s.intersectCaps(r, ts, cone)
+}
+
+func (s *shape) intersectCaps(r localRay, ts []float64, cone bool) []float64 {
+	if !s.closed || math.Abs(r.direction.Y) < epsilon {
+		return ts
+	}
+	for _, y := range [2]float64{s.minimum, s.maximum} {
+		t := (y - r.origin.Y) / r.direction.Y
+		radius := 1.0
+		if cone {
+			radius = math.Abs(y)
+		}
+		p := r.position(t)
+		if p.X*p.X+p.Z*p.Z <= radius*radius {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+func (s *shape) cylinderNormal(p vector.Point, cone bool) vector.Vec3 {
+	dist := p.X*p.X + p.Z*p.Z
+	radius := 1.0
+	if cone {
+		radius = p.Y * p.Y
+	}
+	if dist < radius && p.Y >= s.maximum-epsilon {
+		return vector.Vec3{Y: 1}
+	}
+	if dist < radius && p.Y <= s.minimum+epsilon {
+		return vector.Vec3{Y: -1}
+	}
+	if !cone {
+		return vector.Vec3{X: p.X, Z: p.Z}
+	}
+	y := math.Sqrt(dist)
+	if p.Y > 0 {
+		y = -y
+	}
+	return vector.Vec3{X: p.X, Y: y, Z: p.Z}
+}
+
+// intersectTriangle uses the Möller–Trumbore algorithm
+func (s *shape) intersectTriangle(r localRay, ts []float64) []float64 {
+	dirCrossE2 := r.direction.Cross(s.e2)
+	det := s.e1.Dot(dirCrossE2)
+	if math.Abs(det) < epsilon {
+		return ts
+	}
+	f := 1 / det
+	p1ToOrigin := r.origin.Subtract(s.p1)
+	u := f * p1ToOrigin.Dot(dirCrossE2)
+	if u < 0 || u > 1 {
+		return ts
+	}
+	originCrossE1 := p1ToOrigin.Cross(s.e1)
+	v := f * r.direction.Dot(originCrossE1)
+	if v < 0 || u+v > 1 {
+		return ts
+	}
+	return append(ts, f*s.e2.Dot(originCrossE1))
+}
+
+// localBounds returns the object space box of s, or false if it's infinite
+func (s *shape) localBounds() (box, bool) {
+	switch s.kind {
+	case scene.Sphere, scene.Cube:
+		return box{vector.Point{X: -1, Y: -1, Z: -1}, vector.Point{X: 1, Y: 1, Z: 1}}, true
+	case scene.Cylinder, scene.Cone:
+		if math.IsInf(s.minimum, 0) || math.IsInf(s.maximum, 0) {
+			return box{}, false
+		}
+		radius := 1.0
+		if s.kind == scene.Cone {
+			radius = math.Max(math.Abs(s.minimum), math.Abs(s.maximum))
+		}
+		return box{
+			vector.Point{X: -radius, Y: s.minimum, Z: -radius},
+			vector.Point{X: radius, Y: s.maximum, Z: radius},
+		}, true
+	case scene.Triangle:
+		b := emptyBox()
+		b = b.add(s.p1)
+		b = b.add(s.p1.Add(s.e1))
+		return b.add(s.p1.Add(s.e2)), true
+	}
+	return box{}, false
+}
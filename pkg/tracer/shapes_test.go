@@ -0,0 +1,138 @@
+package tracer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newShape(t *testing.T, o *scene.Object) *shape {
+	t.Helper()
+	shapes, err := flatten(o, matrix.Identity(4), 0)
+	assert.NoError(t, err)
+	return shapes[0]
+}
+
+func truncated(kind string, min, max float64, closed bool) *scene.Object {
+	o := object(kind, matrix.Identity(4), scene.DefaultMaterial())
+	o.Minimum, o.Maximum, o.Closed = min, max, closed
+	return o
+}
+
+func triangle() *scene.Object {
+	o := object(scene.Triangle, matrix.Identity(4), scene.DefaultMaterial())
+	o.Points = []*vector.Vector{vector.NewPoint(0, 1, 0), vector.NewPoint(-1, 0, 0), vector.NewPoint(1, 0, 0)}
+	return o
+}
+
+func TestIntersect(t *testing.T) {
+	identity := matrix.Identity(4)
+	sphere := object(scene.Sphere, identity, scene.DefaultMaterial())
+	tests := map[string]struct {
+		o                 *scene.Object
+		origin, direction vector.Vector
+		want              []float64
+	}{
+		"sphere":                {o: sphere, origin: *vector.NewPoint(0, 0, -5), direction: *vector.NewVector(0, 0, 1), want: []float64{4, 6}},
+		"sphere tangent":        {o: sphere, origin: *vector.NewPoint(0, 1, -5), direction: *vector.NewVector(0, 0, 1), want: []float64{5, 5}},
+		"sphere miss":           {o: sphere, origin: *vector.NewPoint(0, 2, -5), direction: *vector.NewVector(0, 0, 1)},
+		"scaled sphere":         {o: object(scene.Sphere, matrix.Scaling(2, 2, 2), scene.DefaultMaterial()), origin: *vector.NewPoint(0, 0, -5), direction: *vector.NewVector(0, 0, 1), want: []float64{3, 7}},
+		"plane from above":      {o: object(scene.Plane, identity, scene.DefaultMaterial()), origin: *vector.NewPoint(0, 1, 0), direction: *vector.NewVector(0, -1, 0), want: []float64{1}},
+		"parallel plane":        {o: object(scene.Plane, identity, scene.DefaultMaterial()), origin: *vector.NewPoint(0, 10, 0), direction: *vector.NewVector(0, 0, 1)},
+		"cube":                  {o: object(scene.Cube, identity, scene.DefaultMaterial()), origin: *vector.NewPoint(5, 0.5, 0), direction: *vector.NewVector(-1, 0, 0), want: []float64{4, 6}},
+		"cube from inside":      {o: object(scene.Cube, identity, scene.DefaultMaterial()), origin: *vector.NewPoint(0, 0.5, 0), direction: *vector.NewVector(0, 0, 1), want: []float64{-1, 1}},
+		"cube miss":             {o: object(scene.Cube, identity, scene.DefaultMaterial()), origin: *vector.NewPoint(2, 0, 2), direction: *vector.NewVector(0, 0, -1)},
+		"cylinder":              {o: truncated(scene.Cylinder, math.Inf(-1), math.Inf(1), false), origin: *vector.NewPoint(0, 0, -5), direction: *vector.NewVector(0, 0, 1), want: []float64{4, 6}},
+		"cylinder along axis":   {o: truncated(scene.Cylinder, math.Inf(-1), math.Inf(1), false), origin: *vector.NewPoint(0, 0, 0), direction: *vector.NewVector(0, 1, 0)},
+		"truncated cylinder":    {o: truncated(scene.Cylinder, 1, 2, false), origin: *vector.NewPoint(0, 1.5, -2), direction: *vector.NewVector(0, 0, 1), want: []float64{1, 3}},
+		"above truncation":      {o: truncated(scene.Cylinder, 1, 2, false), origin: *vector.NewPoint(0, 3, -5), direction: *vector.NewVector(0, 0, 1)},
+		"cylinder caps":         {o: truncated(scene.Cylinder, 1, 2, true), origin: *vector.NewPoint(0, 3, 0), direction: *vector.NewVector(0, -1, 0), want: []float64{2, 1}},
+		"cone":                  {o: truncated(scene.Cone, math.Inf(-1), math.Inf(1), false), origin: *vector.NewPoint(0, 0, -5), direction: *vector.NewVector(0, 0, 1), want: []float64{5, 5}},
+		"cone parallel to half": {o: truncated(scene.Cone, math.Inf(-1), math.Inf(1), false), origin: *vector.NewPoint(0, 0, -1), direction: *vector.NewVector(0, 1, 1), want: []float64{0.25}},
+		"cone caps":             {o: truncated(scene.Cone, -0.5, 0.5, true), origin: *vector.NewPoint(0, 0, -0.25), direction: *vector.NewVector(0, 1, 0), want: []float64{0.25, -0.25, -0.5, 0.5}},
+		"triangle":              {o: triangle(), origin: *vector.NewPoint(0, 0.5, -2), direction: *vector.NewVector(0, 0, 1), want: []float64{2}},
+		"triangle edge miss":    {o: triangle(), origin: *vector.NewPoint(1, 1, -2), direction: *vector.NewVector(0, 0, 1)},
+		"parallel triangle":     {o: triangle(), origin: *vector.NewPoint(0, -1, -2), direction: *vector.NewVector(0, 1, 0)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := newShape(t, tc.o)
+			origin, _ := tc.origin.Point()
+			direction, _ := tc.direction.Vec3()
+			got := s.intersect(localRay{origin, direction}, nil)
+			if assert.Len(t, got, len(tc.want)) {
+				for i := range got {
+					assert.InDelta(t, tc.want[i], got[i], 1e-5)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalAt(t *testing.T) {
+	transformed, err := matrix.Chain(matrix.RotationZ(math.Pi/5), matrix.Scaling(1, 0.5, 1))
+	assert.NoError(t, err)
+	tests := map[string]struct {
+		o     *scene.Object
+		point vector.Point
+		want  vector.Normal
+	}{
+		"sphere":             {o: object(scene.Sphere, matrix.Identity(4), scene.DefaultMaterial()), point: vector.Point{X: 1}, want: vector.Normal{X: 1}},
+		"translated sphere":  {o: object(scene.Sphere, matrix.Translation(0, 1, 0), scene.DefaultMaterial()), point: vector.Point{Y: 1.70711, Z: -0.70711}, want: vector.Normal{Y: 0.70711, Z: -0.70711}},
+		"transformed sphere": {o: object(scene.Sphere, transformed, scene.DefaultMaterial()), point: vector.Point{Y: math.Sqrt2 / 2, Z: -math.Sqrt2 / 2}, want: vector.Normal{Y: 0.97014, Z: -0.24254}},
+		"plane":              {o: object(scene.Plane, matrix.Identity(4), scene.DefaultMaterial()), point: vector.Point{X: 10, Z: -10}, want: vector.Normal{Y: 1}},
+		"cube":               {o: object(scene.Cube, matrix.Identity(4), scene.DefaultMaterial()), point: vector.Point{X: -0.4, Y: 0.3, Z: -1}, want: vector.Normal{Z: -1}},
+		"cube corner":        {o: object(scene.Cube, matrix.Identity(4), scene.DefaultMaterial()), point: vector.Point{X: 1, Y: 1, Z: 1}, want: vector.Normal{X: 1}},
+		"cylinder":           {o: truncated(scene.Cylinder, math.Inf(-1), math.Inf(1), false), point: vector.Point{Y: 5, Z: -1}, want: vector.Normal{Z: -1}},
+		"cylinder cap":       {o: truncated(scene.Cylinder, 1, 2, true), point: vector.Point{X: 0.5, Y: 2}, want: vector.Normal{Y: 1}},
+		"cone":               {o: truncated(scene.Cone, math.Inf(-1), math.Inf(1), false), point: vector.Point{X: 1, Y: 1, Z: 1}, want: vector.Normal{X: 0.5, Y: -math.Sqrt2 / 2, Z: 0.5}},
+		"triangle":           {o: triangle(), point: vector.Point{X: 0.1, Y: 0.2}, want: vector.Normal{Z: -1}},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := newShape(t, tc.o).normalAt(tc.point)
+			assert.True(t, got.Vector().Equals(tc.want.Vector(), util.Tolerance{Abs: 1e-4}), "got %v", got)
+		})
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	white, black := color.NewColor(1, 1, 1), color.NewColor(0, 0, 0)
+	tests := map[string]struct {
+		pattern string
+		point   vector.Point
+		want    *color.Color
+	}{
+		"stripes":                 {pattern: scene.Stripes, point: vector.Point{X: 0.9, Y: 5}, want: white},
+		"next stripe":             {pattern: scene.Stripes, point: vector.Point{X: 1}, want: black},
+		"negative stripe":         {pattern: scene.Stripes, point: vector.Point{X: -0.1}, want: black},
+		"second negative stripe":  {pattern: scene.Stripes, point: vector.Point{X: -1.1}, want: white},
+		"gradient":                {pattern: scene.Gradient, point: vector.Point{X: 0.25}, want: color.NewColor(0.75, 0.75, 0.75)},
+		"ring":                    {pattern: scene.Ring, point: vector.Point{X: 0.708, Z: 0.708}, want: black},
+		"ring center":             {pattern: scene.Ring, point: vector.Point{X: 0.5}, want: white},
+		"checkers":                {pattern: scene.Checkers, point: vector.Point{X: 0.99, Y: 0.99, Z: 0.99}, want: white},
+		"checkers next to origin": {pattern: scene.Checkers, point: vector.Point{Y: 1.01}, want: black},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &scene.Pattern{Type: tc.pattern, Colors: []*color.Color{white, black}}
+			assertColor(t, tc.want, patternColor(p, tc.point))
+		})
+	}
+
+	// Patterns are placed by both the object and the pattern transformation
+	m := scene.DefaultMaterial()
+	m.Pattern = &scene.Pattern{Type: scene.Stripes, Colors: []*color.Color{white, black}, Transform: matrix.Translation(0.5, 0, 0)}
+	s := newShape(t, object(scene.Sphere, matrix.Scaling(2, 2, 2), m))
+	assertColor(t, white, albedo(s, vector.Point{X: 2.5}))
+	assertColor(t, black, albedo(s, vector.Point{X: 3.5}))
+}
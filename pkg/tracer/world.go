@@ -0,0 +1,195 @@
+// Package tracer traces rays through a loaded scene, shading what they hit with
+// the Phong reflection model, shadows, reflections and refractions.
+package tracer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/ray"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/render"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+)
+
+// DefaultMaxDepth is how many times rays are reflected or refracted by default
+const DefaultMaxDepth = 5
+
+// World is a scene prepared for tracing: groups are flattened into shapes placed
+// directly in world space and bounded shapes are put into a hierarchy of boxes
+type World struct {
+	// Background is the color of rays that don't hit anything. If it's nil,
+	// such camera rays return nil, which makes them transparent, and reflected
+	// or refracted rays see black.
+	Background *color.Color
+	// MaxDepth limits how many times rays are reflected or refracted
+	MaxDepth int
+
+	lights    []light
+	unbounded []*shape
+	bounded   *bvh
+}
+
+type light struct {
+	position  vector.Point
+	intensity *color.Color
+}
+
+// hit is a single intersection of a ray with a shape
+type hit struct {
+	t float64
+	s *shape
+}
+
+// NewWorld prepares scene s for tracing. It returns error if a transformation of
+// an object or a pattern can't be inverted.
+func NewWorld(s *scene.Scene) (*World, error) {
+	w := &World{Background: s.Background, MaxDepth: DefaultMaxDepth}
+	for _, l := range s.Lights {
+		p, err := l.Position.Point()
+		if err != nil {
+			return nil, fmt.Errorf("light position: %v", err)
+		}
+		w.lights = append(w.lights, light{p, l.Intensity})
+	}
+
+	var bounded []*shape
+	for i, o := range s.Objects {
+		shapes, err := flatten(o, matrix.Identity(4), i)
+		if err != nil {
+			return nil, err
+		}
+		for _, sh := range shapes {
+			if sh.bounded {
+				bounded = append(bounded, sh)
+			} else {
+				w.unbounded = append(w.unbounded, sh)
+			}
+		}
+	}
+	if len(bounded) > 0 {
+		w.bounded = newBVH(bounded)
+	}
+	return w, nil
+}
+
+// flatten returns the shapes of object o and of all of its children, where
+// parent is the transformation of the group o is in
+func flatten(o *scene.Object, parent *matrix.Matrix, object int) ([]*shape, error) {
+	transform, err := matrix.Multiply(parent, o.Transform)
+	if err != nil {
+		return nil, objectError(o, err)
+	}
+	if o.Type == scene.Group {
+		var result []*shape
+		for _, child := range o.Children {
+			shapes, err := flatten(child, transform, object)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, shapes...)
+		}
+		return result, nil
+	}
+
+	s := &shape{
+		kind:        o.Type,
+		object:      object,
+		material:    o.Material,
+		castsShadow: o.CastsShadow,
+		minimum:     o.Minimum,
+		maximum:     o.Maximum,
+		closed:      o.Closed,
+	}
+	if s.inverse, err = matrix.GetInverse(transform); err != nil {
+		return nil, objectError(o, err)
+	}
+	s.normal = matrix.Transpose(s.inverse)
+	if pattern := o.Material.Pattern; pattern != nil {
+		if s.patternInverse, err = matrix.GetInverse(pattern.Transform); err != nil {
+			return nil, objectError(o, fmt.Errorf("pattern: %v", err))
+		}
+	}
+	if o.Type == scene.Triangle {
+		var points [3]vector.Point
+		for i, p := range o.Points {
+			if points[i], err = p.Point(); err != nil {
+				return nil, objectError(o, err)
+			}
+		}
+		s.p1 = points[0]
+		s.e1 = points[1].Subtract(points[0])
+		s.e2 = points[2].Subtract(points[0])
+		if s.triangleNormal, err = s.e2.Cross(s.e1).Normal(); err != nil {
+			return nil, objectError(o, fmt.Errorf("degenerate triangle: %v", err))
+		}
+	}
+	if b, ok := s.localBounds(); ok {
+		s.bounds = pad(b.transform(transform))
+		s.bounded = true
+	}
+	return []*shape{s}, nil
+}
+
+func objectError(o *scene.Object, err error) error {
+	return fmt.Errorf("%s:%d: %s: %v", o.File, o.Line, o.Type, err)
+}
+
+// pad grows b slightly, so that flat shapes like triangles lying in a plane of
+// the axes don't fall through their boxes because of rounding errors
+func pad(b box) box {
+	d := vector.Vec3{X: epsilon, Y: epsilon, Z: epsilon}
+	return box{b.min.Add(d.Negate()), b.max.Add(d)}
+}
+
+// Color returns the color seen along camera ray r
+func (w *World) Color(r *ray.Ray) *color.Color {
+	return w.Shade(r, nil)
+}
+
+// Shade returns the color seen along camera ray r and stores values of the
+// standard render passes for the first hit in aovs, unless it's nil
+func (w *World) Shade(r *ray.Ray, aovs render.AOVs) *color.Color {
+	origin, err := r.Origin.Point()
+	if err != nil {
+		return nil
+	}
+	direction, err := r.Direction.Vec3()
+	if err != nil {
+		return nil
+	}
+	return w.colorAt(localRay{origin, direction}, w.MaxDepth, aovs)
+}
+
+// intersect returns every hit of r sorted by distance
+func (w *World) intersect(r localRay) []hit {
+	hits := intersectShapes(w.unbounded, r, nil)
+	if w.bounded != nil {
+		hits = w.bounded.intersect(r, hits)
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].t < hits[j].t })
+	return hits
+}
+
+func intersectShapes(shapes []*shape, r localRay, hits []hit) []hit {
+	var ts [4]float64
+	for _, s := range shapes {
+		for _, t := range s.intersect(r, ts[:0]) {
+			hits = append(hits, hit{t, s})
+		}
+	}
+	return hits
+}
+
+// firstHit returns the index of the closest hit in front of the ray origin, or
+// -1 if there is none
+func firstHit(hits []hit) int {
+	for i, h := range hits {
+		if h.t >= 0 {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,268 @@
+package tracer
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/alex-petrov-vt/raytracer/pkg/models/color"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/matrix"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/ray"
+	"github.com/alex-petrov-vt/raytracer/pkg/models/vector"
+	"github.com/alex-petrov-vt/raytracer/pkg/render"
+	"github.com/alex-petrov-vt/raytracer/pkg/scene"
+	"github.com/alex-petrov-vt/raytracer/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func object(kind string, transform *matrix.Matrix, m *scene.Material) *scene.Object {
+	return &scene.Object{
+		Type:        kind,
+		Transform:   transform,
+		Material:    m,
+		CastsShadow: true,
+		Minimum:     math.Inf(-1),
+		Maximum:     math.Inf(1),
+	}
+}
+
+func pointLight(x, y, z float64) *scene.Light {
+	return &scene.Light{Position: vector.NewPoint(x, y, z), Intensity: color.NewColor(1, 1, 1)}
+}
+
+// defaultScene has two concentric spheres lit from the top left
+func defaultScene() *scene.Scene {
+	outer := scene.DefaultMaterial()
+	outer.Color = color.NewColor(0.8, 1, 0.6)
+	outer.Diffuse = 0.7
+	outer.Specular = 0.2
+	return &scene.Scene{
+		Lights: []*scene.Light{pointLight(-10, 10, -10)},
+		Objects: []*scene.Object{
+			object(scene.Sphere, matrix.Identity(4), outer),
+			object(scene.Sphere, matrix.Scaling(0.5, 0.5, 0.5), scene.DefaultMaterial()),
+		},
+		Background: color.NewColor(0, 0, 0),
+	}
+}
+
+func newWorld(t *testing.T, s *scene.Scene) *World {
+	t.Helper()
+	w, err := NewWorld(s)
+	assert.NoError(t, err)
+	return w
+}
+
+func cameraRay(ox, oy, oz, dx, dy, dz float64) *ray.Ray {
+	return ray.NewRay(vector.NewPoint(ox, oy, oz), vector.NewVector(dx, dy, dz))
+}
+
+func assertColor(t *testing.T, want, got *color.Color) {
+	t.Helper()
+	if !assert.NotNil(t, got) {
+		return
+	}
+	assert.True(t, color.Equals(want, got, util.Tolerance{Abs: 1e-4}), "expected %v, got %v", want, got)
+}
+
+func TestColor(t *testing.T) {
+	w := newWorld(t, defaultScene())
+	assertColor(t, color.NewColor(0.38066, 0.47583, 0.2855), w.Color(cameraRay(0, 0, -5, 0, 0, 1)))
+	assertColor(t, color.NewColor(0, 0, 0), w.Color(cameraRay(0, 0, -5, 0, 1, 0)))
+
+	w.Background = nil
+	assert.Nil(t, w.Color(cameraRay(0, 0, -5, 0, 1, 0)))
+}
+
+func TestColorFromInside(t *testing.T) {
+	s := defaultScene()
+	s.Objects[0].Material.Ambient = 1
+	s.Objects[1].Material.Ambient = 1
+	w := newWorld(t, s)
+
+	// The ray starts between the spheres and only sees the inner one
+	assertColor(t, s.Objects[1].Material.Color, w.Color(cameraRay(0, 0, 0.75, 0, 0, -1)))
+}
+
+func TestShadows(t *testing.T) {
+	s := &scene.Scene{
+		Lights: []*scene.Light{pointLight(0, 0, -10)},
+		Objects: []*scene.Object{
+			object(scene.Sphere, matrix.Identity(4), scene.DefaultMaterial()),
+			object(scene.Sphere, matrix.Translation(0, 0, 10), scene.DefaultMaterial()),
+		},
+	}
+	w := newWorld(t, s)
+	assertColor(t, color.NewColor(0.1, 0.1, 0.1), w.Color(cameraRay(0, 0, 5, 0, 0, 1)))
+
+	s.Objects[0].CastsShadow = false
+	w = newWorld(t, s)
+	assertColor(t, color.NewColor(1.9, 1.9, 1.9), w.Color(cameraRay(0, 0, 5, 0, 0, 1)))
+}
+
+func TestReflection(t *testing.T) {
+	s := defaultScene()
+	m := scene.DefaultMaterial()
+	m.Reflective = 0.5
+	s.Objects = append(s.Objects, object(scene.Plane, matrix.Translation(0, -1, 0), m))
+	w := newWorld(t, s)
+
+	r := cameraRay(0, 0, -3, 0, -math.Sqrt2/2, math.Sqrt2/2)
+	assertColor(t, color.NewColor(0.87677, 0.92436, 0.82918), w.Color(r))
+
+	// Without any depth left the plane is shaded like it isn't reflective
+	w.MaxDepth = 0
+	got := w.Color(r)
+	m.Reflective = 0
+	assertColor(t, newWorld(t, s).Color(r), got)
+}
+
+func TestMutuallyReflectiveSurfaces(t *testing.T) {
+	m := scene.DefaultMaterial()
+	m.Reflective = 1
+	s := &scene.Scene{
+		Lights: []*scene.Light{pointLight(0, 0, 0)},
+		Objects: []*scene.Object{
+			object(scene.Plane, matrix.Translation(0, -1, 0), m),
+			object(scene.Plane, matrix.Translation(0, 1, 0), m),
+		},
+	}
+	w := newWorld(t, s)
+	assert.NotNil(t, w.Color(cameraRay(0, 0, 0, 0, 1, 0)))
+}
+
+func TestRefraction(t *testing.T) {
+	s := defaultScene()
+	floor := scene.DefaultMaterial()
+	floor.Reflective = 0.5
+	floor.Transparency = 0.5
+	floor.RefractiveIndex = 1.5
+	ball := scene.DefaultMaterial()
+	ball.Color = color.NewColor(1, 0, 0)
+	ball.Ambient = 0.5
+	s.Objects = append(s.Objects,
+		object(scene.Plane, matrix.Translation(0, -1, 0), floor),
+		object(scene.Sphere, matrix.Translation(0, -3.5, -0.5), ball),
+	)
+	w := newWorld(t, s)
+
+	r := cameraRay(0, 0, -3, 0, -math.Sqrt2/2, math.Sqrt2/2)
+	assertColor(t, color.NewColor(0.93391, 0.69643, 0.69243), w.Color(r))
+}
+
+func TestRefractiveIndices(t *testing.T) {
+	glass := func(index float64) *shape {
+		m := scene.DefaultMaterial()
+		m.RefractiveIndex = index
+		return &shape{material: m}
+	}
+	a, b, c := glass(1.5), glass(2), glass(2.5)
+	hits := []hit{{2, a}, {2.75, b}, {3.25, c}, {4.75, b}, {5.25, c}, {6, a}}
+	want := [][2]float64{{1, 1.5}, {1.5, 2}, {2, 2.5}, {2.5, 2.5}, {2.5, 1.5}, {1.5, 1}}
+
+	for i := range hits {
+		n1, n2 := refractiveIndices(hits, i)
+		assert.Equal(t, want[i], [2]float64{n1, n2}, "hit %v", i)
+	}
+}
+
+func TestShadePasses(t *testing.T) {
+	w := newWorld(t, defaultScene())
+	aovs := render.AOVs{}
+	w.Shade(cameraRay(0, 0, -5, 0, 0, 1), aovs)
+
+	assertColor(t, color.NewColor(4, 4, 4), aovs[render.Depth.Name])
+	assertColor(t, color.NewColor(0, 0, -1), aovs[render.Normal.Name])
+	assertColor(t, color.NewColor(0.8, 1, 0.6), aovs[render.Albedo.Name])
+	assertColor(t, color.NewColor(1, 1, 1), aovs[render.ObjectID.Name])
+	assertColor(t, color.NewColor(0, 0, 0), aovs[render.Shadow.Name])
+	assertColor(t, color.NewColor(0, 0, 0), aovs[render.Reflection.Name])
+
+	aovs = render.AOVs{}
+	w.Shade(cameraRay(0, 0, -5, 0, 1, 0), aovs)
+	assert.Empty(t, aovs)
+}
+
+func TestGroups(t *testing.T) {
+	group := object(scene.Group, matrix.Scaling(2, 2, 2), scene.DefaultMaterial())
+	group.Children = []*scene.Object{
+		object(scene.Sphere, matrix.Translation(5, 0, 0), scene.DefaultMaterial()),
+	}
+	w := newWorld(t, &scene.Scene{Objects: []*scene.Object{
+		object(scene.Plane, matrix.Translation(0, -10, 0), scene.DefaultMaterial()),
+		group,
+	}})
+
+	hits := w.intersect(localRay{vector.Point{X: 10, Y: 0, Z: -10}, vector.Vec3{Z: 1}})
+	if assert.Len(t, hits, 2) {
+		assert.InDelta(t, 8, hits[0].t, 1e-9)
+		assert.InDelta(t, 12, hits[1].t, 1e-9)
+		assert.Equal(t, 1, hits[0].s.object)
+	}
+}
+
+func TestBVHMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var objects []*scene.Object
+	for i := 0; i < 60; i++ {
+		transform, err := matrix.Chain(
+			matrix.Scaling(0.2+r.Float64(), 0.2+r.Float64(), 0.2+r.Float64()),
+			matrix.RotationY(r.Float64()*math.Pi),
+			matrix.Translation(r.Float64()*20-10, r.Float64()*20-10, r.Float64()*20-10),
+		)
+		assert.NoError(t, err)
+		kind := []string{scene.Sphere, scene.Cube, scene.Triangle}[i%3]
+		o := object(kind, transform, scene.DefaultMaterial())
+		o.Points = []*vector.Vector{vector.NewPoint(0, 1, 0), vector.NewPoint(-1, 0, 0), vector.NewPoint(1, 0, 0)}
+		objects = append(objects, o)
+	}
+	w := newWorld(t, &scene.Scene{Objects: objects})
+	var all []*shape
+	for _, o := range objects {
+		shapes, err := flatten(o, matrix.Identity(4), 0)
+		assert.NoError(t, err)
+		all = append(all, shapes...)
+	}
+
+	for i := 0; i < 200; i++ {
+		origin := vector.Point{X: r.Float64()*30 - 15, Y: r.Float64()*30 - 15, Z: -20}
+		direction := vector.Vec3{X: r.Float64() - 0.5, Y: r.Float64() - 0.5, Z: 1}
+		lr := localRay{origin, direction}
+
+		got := w.intersect(lr)
+		want := intersectShapes(all, lr, nil)
+		inFront := 0
+		for _, h := range want {
+			if h.t >= 0 {
+				inFront++
+			}
+		}
+		gotInFront := 0
+		for _, h := range got {
+			if h.t >= 0 {
+				gotInFront++
+			}
+		}
+		assert.Equal(t, inFront, gotInFront, "ray %v", i)
+	}
+}
+
+func TestNewWorldErrors(t *testing.T) {
+	singular := object(scene.Sphere, matrix.Scaling(1, 0, 1), scene.DefaultMaterial())
+	singular.File, singular.Line = "scene.yml", 12
+	_, err := NewWorld(&scene.Scene{Objects: []*scene.Object{singular}})
+	if assert.Error(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), "scene.yml:12: sphere:"), err.Error())
+	}
+
+	m := scene.DefaultMaterial()
+	m.Pattern = &scene.Pattern{Type: scene.Stripes, Transform: matrix.Scaling(0, 1, 1)}
+	_, err = NewWorld(&scene.Scene{Objects: []*scene.Object{object(scene.Sphere, matrix.Identity(4), m)}})
+	assert.Error(t, err)
+
+	flat := object(scene.Triangle, matrix.Identity(4), scene.DefaultMaterial())
+	flat.Points = []*vector.Vector{vector.NewPoint(0, 0, 0), vector.NewPoint(1, 1, 1), vector.NewPoint(2, 2, 2)}
+	_, err = NewWorld(&scene.Scene{Objects: []*scene.Object{flat}})
+	assert.Error(t, err)
+}
@@ -4,9 +4,73 @@ import (
 	"math"
 )
 
-const float64EqualityThreshold = 1e-5
+// Tolerance describes how close two float64 numbers have to be to be treated as
+// equal: within Abs of each other, or within Rel of the larger magnitude. The
+// absolute part matters near zero and the relative one for large coordinates.
+type Tolerance struct {
+	Abs, Rel float64
+}
+
+// DefaultTolerance is used by FloatEquals and by the Equals functions of the
+// models when no tolerance is given. It isn't synchronized, so change it only
+// before rendering starts.
+var DefaultTolerance = Tolerance{Abs: 1e-5, Rel: 1e-9}
+
+// Equals compares a and b using t
+func (t Tolerance) Equals(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+	diff := math.Abs(a - b)
+	if diff <= t.Abs {
+		return true
+	}
+	return diff <= t.Rel*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// ToleranceOrDefault returns the first of tol, or DefaultTolerance if it's
+// empty, for functions taking an optional tolerance
+func ToleranceOrDefault(tol ...Tolerance) Tolerance {
+	if len(tol) > 0 {
+		return tol[0]
+	}
+	return DefaultTolerance
+}
 
-// FloatEquals compares two float64 numbers using epsilon
+// FloatEquals compares two float64 numbers using DefaultTolerance
 func FloatEquals(a, b float64) bool {
-	return math.Abs(a-b) <= float64EqualityThreshold
+	return DefaultTolerance.Equals(a, b)
+}
+
+// ULPDistance returns the number of representable float64 numbers between a
+// and b, counting +0 and -0 as the same number. It returns math.MaxUint64 if
+// either of them is NaN.
+func ULPDistance(a, b float64) uint64 {
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return math.MaxUint64
+	}
+	ia, ib := orderedBits(a), orderedBits(b)
+	if ia > ib {
+		return uint64(ia - ib)
+	}
+	return uint64(ib - ia)
+}
+
+// FloatEqualsULP reports whether a and b are at most ulps representable
+// float64 numbers apart, which scales with their magnitude on its own
+func FloatEqualsULP(a, b float64, ulps uint64) bool {
+	return ULPDistance(a, b) <= ulps
+}
+
+// orderedBits maps the bits of f to an integer that orders the same way as the
+// float64 numbers do, with negative numbers mirrored below zero
+func orderedBits(f float64) int64 {
+	bits := int64(math.Float64bits(f))
+	if bits < 0 {
+		return math.MinInt64 - bits
+	}
+	return bits
 }
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"math"
 	"testing"
 )
 
@@ -16,6 +17,12 @@ func TestFloatEquals(t *testing.T) {
 		"not equal small numbers": {input1: (0.005 * 0.005), input2: 0.000026, want: false},
 		"equal big numbers":       {input1: (1234.5 * 6789.0), input2: 8381020.5, want: true},
 		"not equal big numbers":   {input1: (1234.5 * 6789.0), input2: 8381020.6, want: false},
+		"city scale":              {input1: 1e7 + 1e-4, input2: 1e7, want: true},
+		"far apart city scale":    {input1: 1e7 + 1e-1, input2: 1e7, want: false},
+		"infinity":                {input1: math.Inf(1), input2: math.Inf(1), want: true},
+		"NaN":                     {input1: math.NaN(), input2: math.NaN(), want: false},
+		"opposite infinities":     {input1: math.Inf(1), input2: math.Inf(-1), want: false},
+		"infinity and number":     {input1: math.Inf(1), input2: 1e300, want: false},
 	}
 
 	for name, tc := range tests {
@@ -25,3 +32,66 @@ func TestFloatEquals(t *testing.T) {
 		}
 	}
 }
+
+func TestToleranceEquals(t *testing.T) {
+	strict := Tolerance{Abs: 1e-12, Rel: 1e-9}
+	tests := map[string]struct {
+		tol    Tolerance
+		input1 float64
+		input2 float64
+		want   bool
+	}{
+		"not equal small numbers": {tol: strict, input1: (0.005 * 0.005), input2: 0.000026, want: false},
+		"equal small numbers":     {tol: strict, input1: (0.005 * 0.005), input2: 0.000025, want: true},
+		"relative only":           {tol: Tolerance{Rel: 0.01}, input1: 100, input2: 100.5, want: true},
+		"relative only too far":   {tol: Tolerance{Rel: 0.01}, input1: 100, input2: 102, want: false},
+		"relative near zero":      {tol: Tolerance{Rel: 0.01}, input1: 1e-20, input2: 0, want: false},
+		"absolute only":           {tol: Tolerance{Abs: 0.5}, input1: 1e9, input2: 1e9 + 0.25, want: true},
+		"exact":                   {tol: Tolerance{}, input1: 0.5, input2: 0.5, want: true},
+	}
+
+	for name, tc := range tests {
+		got := tc.tol.Equals(tc.input1, tc.input2)
+		if got != tc.want {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+
+	if ToleranceOrDefault() != DefaultTolerance {
+		t.Fatalf("expected default tolerance without arguments")
+	}
+	if ToleranceOrDefault(strict) != strict {
+		t.Fatalf("expected given tolerance")
+	}
+}
+
+func TestULPDistance(t *testing.T) {
+	tests := map[string]struct {
+		input1 float64
+		input2 float64
+		want   uint64
+	}{
+		"same":            {input1: 1, input2: 1, want: 0},
+		"next":            {input1: 1, input2: math.Nextafter(1, 2), want: 1},
+		"previous":        {input1: math.Nextafter(1, 0), input2: 1, want: 1},
+		"signed zeros":    {input1: 0, input2: math.Copysign(0, -1), want: 0},
+		"across zero":     {input1: -math.SmallestNonzeroFloat64, input2: math.SmallestNonzeroFloat64, want: 2},
+		"large magnitude": {input1: 1e15, input2: math.Nextafter(math.Nextafter(1e15, 2e15), 2e15), want: 2},
+		"infinities":      {input1: math.Inf(-1), input2: math.Inf(1), want: 0xFFE0000000000000},
+		"NaN":             {input1: math.NaN(), input2: 1, want: math.MaxUint64},
+	}
+
+	for name, tc := range tests {
+		got := ULPDistance(tc.input1, tc.input2)
+		if got != tc.want {
+			t.Fatalf("%s: expected: %v, got %v", name, tc.want, got)
+		}
+	}
+
+	if !FloatEqualsULP(0.1+0.2, 0.3, 1) {
+		t.Fatalf("expected 0.1 + 0.2 to be within 1 ulp of 0.3")
+	}
+	if FloatEqualsULP(1, 1.0000001, 4) {
+		t.Fatalf("expected 1 and 1.0000001 to be more than 4 ulps apart")
+	}
+}